@@ -0,0 +1,45 @@
+package cc
+
+import "time"
+
+// WithAIMD makes the pool adjust its concurrency between minWorkers and maxWorkers from the outcome of the functions,
+// like TCP does with its congestion window: while functions are waiting in the queue and the ones that ended succeeded
+// within target on average, the concurrency grows by one worker, and as soon as a function fails or the average
+// latency exceeds target it's halved. A target of 0 only considers the failures. Useful when talking to services whose
+// capacity changes over time. It's meant to be used instead of WithAutoscale.
+func WithAIMD(minWorkers, maxWorkers int, target time.Duration) Option {
+	return func(p *Pool) {
+		p.aimd = &aimd{min: max(minWorkers, 1), max: max(minWorkers, maxWorkers, 1), target: target}
+	}
+}
+
+type aimd struct {
+	min, max int
+	target   time.Duration
+	last     Stats
+}
+
+// aimdStep looks at the outcomes since the previous step and resizes the pool if needed
+func (p *Pool) aimdStep() {
+	a := p.aimd
+	s := p.Stats()
+	completed := s.Completed - a.last.Completed
+	failed := s.Failed - a.last.Failed
+	var latency time.Duration
+	if completed > 0 {
+		latency = (s.Busy - a.last.Busy) / time.Duration(completed)
+	}
+	a.last = s
+
+	p.mu.Lock()
+	n := p.concurrency
+	p.mu.Unlock()
+	switch {
+	case failed > 0 || a.target > 0 && latency > a.target:
+		if n > a.min {
+			p.Resize(max(n/2, a.min))
+		}
+	case s.Queued > 0 && n < a.max:
+		p.Resize(n + 1)
+	}
+}
@@ -0,0 +1,22 @@
+package cc
+
+import "runtime/metrics"
+
+// WithAllocStats makes the pool measure the bytes allocated while each function runs, reported in TaskInfo.Allocs
+// and in the end events. It's best effort: the allocations of the whole process are counted, so the measure is only
+// accurate for functions running alone, e.g. in a pool of concurrency 1, and it costs a few hundred nanoseconds.
+func WithAllocStats() Option {
+	return func(p *Pool) {
+		p.allocStats = true
+	}
+}
+
+// heapAllocs returns the cumulative bytes allocated on the heap by the process
+func heapAllocs() uint64 {
+	s := []metrics.Sample{{Name: "/gc/heap/allocs:bytes"}}
+	metrics.Read(s)
+	if s[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s[0].Value.Uint64()
+}
@@ -0,0 +1,73 @@
+package cc
+
+import (
+	"runtime"
+	"time"
+)
+
+// autoscaleInterval is how often an autoscaled pool reconsiders its concurrency
+var autoscaleInterval = 100 * time.Millisecond
+
+// NewAuto returns a new pool with a concurrency of runtime.GOMAXPROCS(0), that is the number of functions that can
+// actually run in parallel. Add WithAutoscale to let it grow and shrink with the load.
+func NewAuto(opts ...Option) *Pool {
+	return New(runtime.GOMAXPROCS(0), opts...)
+}
+
+// WithAutoscale makes the pool adjust its concurrency between minWorkers and maxWorkers while it runs: it grows while functions are
+// waiting in the queue and the latency of the functions doesn't degrade, and shrinks while workers are idle.
+func WithAutoscale(minWorkers, maxWorkers int) Option {
+	return func(p *Pool) {
+		p.autoscale = &autoscaler{min: max(minWorkers, 1), max: max(minWorkers, maxWorkers, 1)}
+	}
+}
+
+type autoscaler struct {
+	min, max int
+
+	last    Stats
+	latency time.Duration // the average latency before the last growth, 0 if the last change wasn't a growth
+}
+
+// controlLoop calls step every autoscaleInterval until the pool is finished
+func (p *Pool) controlLoop(step func()) {
+	ticks, stop := tick(p.clock, autoscaleInterval)
+	defer stop()
+	for {
+		select {
+		case <-ticks:
+			step()
+		case <-p.finished:
+			return
+		}
+	}
+}
+
+// autoscaleStep looks at the activity since the previous step and resizes the pool by one worker if needed
+func (p *Pool) autoscaleStep() {
+	a := p.autoscale
+	s := p.Stats()
+	var latency time.Duration
+	if completed := s.Completed - a.last.Completed; completed > 0 {
+		latency = (s.Busy - a.last.Busy) / time.Duration(completed)
+	}
+	a.last = s
+
+	p.mu.Lock()
+	n := p.concurrency
+	p.mu.Unlock()
+	switch {
+	case a.latency > 0 && latency > a.latency*3/2:
+		// the last growth made things slower, e.g. the functions contend on a shared resource
+		a.latency = 0
+		if n > a.min {
+			p.Resize(n - 1)
+		}
+	case s.Queued > 0 && n < a.max:
+		a.latency = latency
+		p.Resize(n + 1)
+	case s.Queued == 0 && s.Running < n && n > a.min:
+		a.latency = 0
+		p.Resize(n - 1)
+	}
+}
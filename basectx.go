@@ -0,0 +1,33 @@
+package cc
+
+import "context"
+
+// WithBaseContext makes the values of ctx, like request IDs or loggers, available to the context of every function
+// of the pool. A value set by the context given to RunCtx takes precedence. Unlike WithContext, the cancellation of ctx
+// doesn't affect the pool.
+func WithBaseContext(ctx context.Context) Option {
+	return func(p *Pool) {
+		p.baseCtx = ctx
+	}
+}
+
+// valuesContext is a context whose values fall back to the ones of another context
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.values.Value(key)
+}
+
+// withBaseValues returns ctx with the values of the base context of the pool, if any
+func (p *Pool) withBaseValues(ctx context.Context) context.Context {
+	if p.baseCtx == nil {
+		return ctx
+	}
+	return valuesContext{Context: ctx, values: p.baseCtx}
+}
@@ -0,0 +1,103 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// RunAll queues all of fns at once and blocks until they end. It returns their errors joined with errors.Join,
+// instead of sending them to Errors, along with the errors Run would return for the functions refused, like
+// ErrCircuitOpen, and ErrPoolClosed if the pool is closed before they could all be queued, or ErrQuotaExceeded if it
+// used up its quota.
+func (p *Pool) RunAll(fns ...func() error) error {
+	return p.RunN(len(fns), func(i int) error {
+		return fns[i]()
+	})
+}
+
+// RunN is like RunAll, for the n calls of fn from fn(0) to fn(n-1)
+func (p *Pool) RunN(n int, fn func(i int) error) error {
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	onDone := func(err error) {
+		if err != nil {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}
+		wg.Done()
+	}
+
+	tasks := make([]*task, n)
+	for i := range tasks {
+		tasks[i] = &task{
+			fn: func(context.Context) error {
+				return fn(i)
+			},
+			onDone: onDone,
+		}
+	}
+	wg.Add(n)
+	queued, err := p.pushAll(tasks)
+	for range tasks[queued:] {
+		wg.Done()
+	}
+	wg.Wait()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// pushAll queues tasks like push does, taking the lock once unless submissions are rate limited, blocking while the
+// queue is full. The tasks refused on their own, because their circuit is open or they don't fit in the memory of the
+// queue, fail with onDone, that they all must have. It returns how many tasks were queued or failed before the pool
+// was closed or used up its quota, and why it stopped then.
+func (p *Pool) pushAll(tasks []*task) (handled int, err error) {
+	var queued, victims, evicted []*task // evicted being refused for lack of memory
+	var refused []func()
+	p.mu.Lock()
+	for _, t := range tasks {
+		if p.submitLimiter != nil {
+			p.mu.Unlock()
+			p.submitLimiter.wait(p.ctx) // it only fails if the pool is cancelled, then t is skipped anyway
+			p.mu.Lock()
+		}
+		for p.state == stateOpen && p.queueSize > 0 && p.queued() >= p.queueSize {
+			p.notFull.Wait()
+		}
+		if err = p.refuse(); err != nil {
+			break
+		}
+		handled++
+		if err := p.checkCircuit(t); err != nil {
+			refused = append(refused, func() { t.onDone(err) })
+			continue
+		}
+		v, err := p.makeRoom(t)
+		if err != nil {
+			refused = append(refused, func() { t.onDone(err) })
+			evicted = append(evicted, t)
+			continue
+		}
+		victims = append(victims, v...)
+		p.enqueue(t)
+		queued = append(queued, t)
+	}
+	p.mu.Unlock()
+	for _, t := range evicted {
+		p.evicted(t, nil)
+	}
+	p.evicted(nil, victims)
+	for _, fail := range refused {
+		fail()
+	}
+	for _, t := range queued {
+		p.emitSubmitted(t.label, t.submitted)
+	}
+	p.runSerial()
+	p.yieldToWorkers()
+	return handled, err
+}
@@ -0,0 +1,102 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunN(t *testing.T) {
+	p := New(3)
+	errs := drain(p)
+	odd := errors.New("odd")
+	var ran atomic.Int32
+	err := p.RunN(10, func(i int) error {
+		ran.Add(1)
+		if i%2 == 1 {
+			return odd
+		}
+		return nil
+	})
+	if ran.Load() != 10 {
+		t.Errorf("RunN returned after %d functions out of 10", ran.Load())
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 5 || !errors.Is(err, odd) {
+		t.Errorf("got %v, want the 5 failures joined", err)
+	}
+	p.Wait()
+	if got := errs(); len(got) != 0 {
+		t.Errorf("got errors %v, want them returned by RunN only", got)
+	}
+	if err := p.RunAll(func() error { return nil }); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("got %v running functions on a closed pool, want ErrPoolClosed", err)
+	}
+}
+
+func TestPushAllCircuitOpen(t *testing.T) {
+	p := New(2, WithCircuitBreaker(1, time.Hour))
+	defer p.WaitErr()
+	failure := errors.New("failure")
+	var wg sync.WaitGroup
+	wg.Add(1)
+	p.push(&task{
+		label:  "broken",
+		fn:     func(context.Context) error { return failure },
+		onDone: func(error) { wg.Done() },
+	})
+	wg.Wait()
+
+	errs := make([]error, 3)
+	ran := make([]bool, 3)
+	tasks := make([]*task, 3)
+	for i, label := range []string{"broken", "healthy", "broken"} {
+		tasks[i] = &task{
+			label:  label,
+			fn:     func(context.Context) error { ran[i] = true; return nil },
+			onDone: func(err error) { errs[i] = err; wg.Done() },
+		}
+	}
+	wg.Add(len(tasks))
+	if handled, err := p.pushAll(tasks); handled != 3 || err != nil {
+		t.Fatalf("handled %d tasks with %v, want 3", handled, err)
+	}
+	wg.Wait()
+	for i, want := range []error{ErrCircuitOpen, nil, ErrCircuitOpen} {
+		if !errors.Is(errs[i], want) || ran[i] != (want == nil) {
+			t.Errorf("task %d: got %v, ran %v, want %v", i, errs[i], ran[i], want)
+		}
+	}
+}
+
+func TestPushAllQueueMemory(t *testing.T) {
+	var evicted []string
+	p := New(1, WithQueueMemory(10, EvictReject, func(label string, _ int64) { evicted = append(evicted, label) }))
+	defer p.WaitErr()
+	release := make(chan struct{})
+	p.Run(func() { <-release })
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	tasks := make([]*task, 2)
+	for i, label := range []string{"small", "big"} {
+		tasks[i] = &task{
+			label:  label,
+			size:   int64(8 * (i + 1)),
+			fn:     func(context.Context) error { return nil },
+			onDone: func(err error) { errs[i] = err; wg.Done() },
+		}
+	}
+	wg.Add(len(tasks))
+	p.pushAll(tasks)
+	close(release)
+	wg.Wait()
+	if errs[0] != nil || !errors.Is(errs[1], ErrQueueMemory) {
+		t.Errorf("got %v, want the big task refused with ErrQueueMemory", errs)
+	}
+	if len(evicted) != 1 || evicted[0] != "big" {
+		t.Errorf("evicted %v, want [big]", evicted)
+	}
+}
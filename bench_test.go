@@ -0,0 +1,49 @@
+package cc
+
+import (
+	"sync"
+	"testing"
+)
+
+const benchConcurrency = 8
+
+func work() {}
+
+func BenchmarkPoolRun(b *testing.B) {
+	b.ReportAllocs()
+	p := New(benchConcurrency)
+	for range b.N {
+		p.Run(work)
+	}
+	p.WaitErr()
+}
+
+func BenchmarkGoroutinesSemaphore(b *testing.B) {
+	b.ReportAllocs()
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, benchConcurrency)
+	for range b.N {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			work()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRunAllocations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measuring the allocations")
+	}
+	p := New(benchConcurrency)
+	defer p.WaitErr()
+	for range 100 {
+		p.Run(work) // starting the workers and filling the task pool
+	}
+	p.WaitBatch()
+	if n := testing.AllocsPerRun(1000, func() { p.Run(work) }); n > 0.1 {
+		t.Errorf("Run allocated %.2f times per function, want none", n)
+	}
+}
@@ -0,0 +1,113 @@
+package cc
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCircuitOpen is returned when submitting a function whose class is failing, see WithCircuitBreaker
+var ErrCircuitOpen = errors.New("cc: circuit open")
+
+// WithCircuitBreaker makes the pool track the outcome of the functions by label, see RunNamed. When threshold
+// functions with the same label fail in a row the circuit of the label opens: for the next cooldown the submissions
+// with that label fail right away with ErrCircuitOpen, and TryRun refuses them. Then a single failure opens it again,
+// while a success closes it. Functions without a label are not tracked. A label without failures for a cooldown, once
+// its circuit is closed or at the end of its cooldown, is forgotten as if it had just succeeded, so that a pool seeing
+// ever new labels doesn't keep them all.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(p *Pool) {
+		p.breaker = &breaker{
+			threshold: max(threshold, 1),
+			cooldown:  cooldown,
+			circuits:  map[string]*circuit{},
+		}
+	}
+}
+
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	circuits  map[string]*circuit
+	sweepAt   int // the number of circuits beyond which the idle ones are forgotten, see sweep
+}
+
+// circuit is the state of a label
+type circuit struct {
+	failures  int       // the consecutive failures
+	failed    time.Time // the time of the last failure, or of the end of the cooldown once half-open
+	openUntil time.Time // the end of the cooldown, zero when the circuit is closed
+}
+
+// idle reports whether c had no failure for a cooldown, once closed or at the end of its cooldown
+func (b *breaker) idle(c *circuit, now time.Time) bool {
+	return now.Sub(c.failed) > b.cooldown && !now.Before(c.openUntil.Add(b.cooldown))
+}
+
+// sweep forgets the idle circuits when there are many of them, the cost being amortized over the failures that made
+// them. It must be called with p.mu held.
+func (b *breaker) sweep(now time.Time) {
+	if len(b.circuits) < b.sweepAt {
+		return
+	}
+	for label, c := range b.circuits {
+		if b.idle(c, now) {
+			delete(b.circuits, label)
+		}
+	}
+	b.sweepAt = max(2*len(b.circuits), 64)
+}
+
+// checkCircuit returns ErrCircuitOpen if the circuit of the label of t is open. It must be called with p.mu held.
+func (p *Pool) checkCircuit(t *task) error {
+	if p.breaker == nil || t.label == "" {
+		return nil
+	}
+	c := p.breaker.circuits[t.label]
+	if c == nil || c.openUntil.IsZero() {
+		return nil
+	}
+	now := p.clock.Now()
+	if p.breaker.idle(c, now) {
+		delete(p.breaker.circuits, t.label)
+		return nil
+	}
+	if now.Before(c.openUntil) {
+		return ErrCircuitOpen
+	}
+	// half-open: the next failure opens the circuit again, the circuit counting as failed until then
+	c.openUntil = time.Time{}
+	c.failures = p.breaker.threshold - 1
+	c.failed = now
+	return nil
+}
+
+// tripCircuit records the final outcome of a function for its circuit
+func (p *Pool) tripCircuit(label string, err error) {
+	if p.breaker == nil || label == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b := p.breaker
+	c := b.circuits[label]
+	if err == nil {
+		if c != nil {
+			delete(b.circuits, label)
+		}
+		return
+	}
+	now := p.clock.Now()
+	if c != nil && b.idle(c, now) {
+		c = nil
+	}
+	if c == nil {
+		b.sweep(now)
+		c = &circuit{}
+		b.circuits[label] = c
+	}
+	c.failures++
+	c.failed = now
+	if c.failures >= b.threshold {
+		c.openUntil = now.Add(b.cooldown)
+	}
+}
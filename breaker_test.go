@@ -0,0 +1,69 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fail runs a function labeled label failing, and waits for its end
+func fail(p *Pool, label string) error {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	err := p.push(&task{
+		label:  label,
+		fn:     func(context.Context) error { return errors.New("failure") },
+		onDone: func(error) { wg.Done() },
+	})
+	if err == nil {
+		wg.Wait()
+	}
+	return err
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	p := New(1, WithCircuitBreaker(2, 20*time.Millisecond))
+	defer p.WaitErr()
+	fail(p, "x")
+	fail(p, "x")
+	if err := fail(p, "x"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got %v after 2 failures, want ErrCircuitOpen", err)
+	}
+	if err := fail(p, "y"); err != nil {
+		t.Fatalf("got %v for another label", err)
+	}
+	if p.TryRun(func() {}) != true {
+		t.Error("TryRun refused a function without a label")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := fail(p, "x"); err != nil {
+		t.Fatalf("got %v once half-open", err)
+	}
+	if err := fail(p, "x"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got %v after failing half-open, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreakerForgetsIdle(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	p := New(1, WithClock(clock), WithCircuitBreaker(1, time.Minute))
+	defer p.WaitErr()
+	for i := range 1960 {
+		fail(p, strconv.Itoa(i))
+		if i%100 == 99 {
+			clock.Advance(2 * time.Minute)
+		}
+	}
+	p.mu.Lock()
+	n := len(p.breaker.circuits)
+	p.mu.Unlock()
+	if n > 300 {
+		t.Errorf("kept %d circuits, want the idle ones forgotten", n)
+	}
+	if err := fail(p, "1950"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got %v for a circuit opened recently, want ErrCircuitOpen", err)
+	}
+}
@@ -0,0 +1,37 @@
+package cc
+
+import (
+	"bytes"
+	"context"
+)
+
+// maxBufferSize is the capacity above which a buffer isn't reused, so that a single large function doesn't pin
+// its memory in the worker
+const maxBufferSize = 1 << 20
+
+// BufferFrom returns an empty buffer owned by the worker running the function that received ctx, so that the functions
+// encoding or decoding data reuse the same memory rather than allocating their own. The buffer is only valid until the
+// function returns, calling BufferFrom again empties it, and the buffers that grew larger than 1 MiB are not reused.
+// Outside of a function run by a pool BufferFrom returns a new buffer. The reuses are counted in Stats.
+func BufferFrom(ctx context.Context) *bytes.Buffer {
+	w := workerFrom(ctx)
+	if w == nil {
+		return new(bytes.Buffer)
+	}
+	if w.buf == nil || w.buf.Cap() > maxBufferSize {
+		w.buf = new(bytes.Buffer)
+		w.pool.bufferMisses.Add(1)
+		return w.buf
+	}
+	w.buf.Reset()
+	w.pool.bufferHits.Add(1)
+	return w.buf
+}
+
+// BufferHitRate returns the share of the buffers returned by BufferFrom that were reused, between 0 and 1
+func (s Stats) BufferHitRate() float64 {
+	if s.BufferReuses+s.BufferAllocs == 0 {
+		return 0
+	}
+	return float64(s.BufferReuses) / float64(s.BufferReuses+s.BufferAllocs)
+}
@@ -0,0 +1,60 @@
+package cc
+
+import (
+	"context"
+	"errors"
+)
+
+// The reasons of the cancellations by a pool, see CancelError
+var (
+	ErrSiblingFailed = errors.New("cc: cancelled, another function failed") // with WithFailFast
+	ErrStopped       = errors.New("cc: cancelled, the pool was stopped")    // by Stop giving up waiting, or StopOnSignal
+	ErrShutdown      = errors.New("cc: cancelled, the pool shut down")      // once all the functions ended after Wait
+	ErrTaskTimeout   = errors.New("cc: cancelled, the function timed out")  // see RunWithTimeout and WithTaskTimeout
+)
+
+// CancelError is the cause, see context.Cause, of the contexts of the functions cancelled by their pool, telling why:
+// its Reason is one of ErrSiblingFailed, ErrStopped, ErrShutdown and ErrTaskTimeout, and Err is what triggered it, e.g.
+// the error of the function that failed first, or the cause of the context given to Stop. Both match with errors.Is:
+//
+//	if errors.Is(context.Cause(ctx), cc.ErrSiblingFailed) {
+//		return nil // not worth reporting
+//	}
+//
+// The functions abandoned by WithEscalation and the losing attempts of RunHedged have ErrEscalated and ErrHedgeLost as
+// their cause instead, while an open circuit refuses the submissions with ErrCircuitOpen rather than cancelling them.
+type CancelError struct {
+	Reason error
+	Err    error
+}
+
+func (e *CancelError) Error() string {
+	if e.Err == nil {
+		return e.Reason.Error()
+	}
+	return e.Reason.Error() + ": " + e.Err.Error()
+}
+
+func (e *CancelError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Reason}
+	}
+	return []error{e.Reason, e.Err}
+}
+
+// cancelledBy returns whether cause is a CancelError with the given reason, and not merely wrapping one in its Err
+func cancelledBy(cause error, reason error) bool {
+	e, ok := cause.(*CancelError)
+	return ok && e.Reason == reason
+}
+
+// cancelWith cancels the context of the pool for reason, triggered by err
+func (p *Pool) cancelWith(reason, err error) {
+	p.cancel(&CancelError{Reason: reason, Err: err})
+}
+
+// propagateCause cancels ctx, derived from a context other than the one of the pool, with the cause of the
+// cancellation of the pool. The returned function stops it.
+func (p *Pool) propagateCause(cancel context.CancelCauseFunc) (stop func() bool) {
+	return context.AfterFunc(p.ctx, func() { cancel(context.Cause(p.ctx)) })
+}
@@ -1,6 +1,13 @@
 package cc
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // Pool manages a pool of concurrent workers. It works a bit like a Waitgroup, but with error reporting and concurrency limits
 // You create one with New, and run functions with Run. Then you wait on it like a regular WaitGroup and loop over the errors.
@@ -19,41 +26,352 @@ import "sync"
 //   }
 type Pool struct {
 	Errors chan error
+	// Completions receives a record of each function that ran, if enabled with WithCompletions
+	Completions chan Completion
 
-	semaphore chan bool
-	wg        *sync.WaitGroup
+	name    string
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	baseCtx context.Context // the values given to the functions, see WithBaseContext
+	closers []func()
+
+	mu        sync.Mutex
+	notEmpty  *sync.Cond
+	notFull   *sync.Cond
+	slotFree  *sync.Cond // signaled when slots are freed for the child pools, see Child
+	queue     taskQueue
+	queueSize int // 0 means unbounded
+	memLimit  int64
+	eviction  Eviction
+	onEvict   func(label string, size int64)
+	keys      map[string]*keyState
+	keyLimit  int                   // the number of tasks allowed in flight per key, see WithPerKeyLimit
+	classes   map[string]*keyState  // the classes of tasks and their limits, see WithLimits
+	stealing  bool                  // whether the idle workers may run tasks beyond the limit of their class
+	keyQueued int                   // the number of tasks waiting for their key or class, out of the queue
+	flights   map[string]*Task[any] // the tasks run by RunOnce not done yet
+	memos     map[string]*memo      // the tasks run by RunOnce whose result is kept, see WithMemoize
+	memoOrder ring[*memo]           // the results kept, the oldest first, see WithMemoRetention
+	memoTTL   time.Duration
+	tags      map[string]*tagState // the tags with functions pending, see Tag
+	jobs      map[*task]struct{}   // the tasks of RunJob pending, see ExportQueue
+	pending   int                  // the number of tasks submitted and not yet done, including the ones waiting for a retry
+	state     poolState
+	paused    bool
+	finished  chan struct{} // closed once the workers have exited and the channels are closed
+
+	idleWaiters     []chan struct{} // closed when no task is pending anymore
+	pressureWaiters []pressureWaiter
+	onIdle          []func()
+	onShutdown      []func()
+
+	concurrency   int     // the number of workers wanted
+	nworkers      int     // the number of workers alive, started on demand
+	maxGoroutines int     // the cap on the goroutines of the pool, see WithMaxGoroutines
+	compat        Version // the semantics of the pool, see Compat
+	legacyWaiting int     // the goroutines of a V1 pool waiting for a task, see Compat
+	serial        bool    // whether the submitters run the tasks, see NewSerial
+	inline        *worker // the worker of a serial pool
+	inlineRunning bool    // whether a goroutine is running the tasks of a serial pool
+	idleWorkers   int     // the number of workers waiting for a task
+	workerIDs     []bool  // the IDs taken by the workers alive
+	used          int     // the number of slots taken by the running functions, including the ones of the child pools
+	throttled     int     // the number of slots held back, see WithLowPriority
+	slotWaiters   int     // the number of child workers waiting for free slots
+	parent        *Pool
+	workers       sync.WaitGroup
+	initialized   int             // the number of workers alive that ran their init hook
+	warmWaiters   []chan struct{} // closed once all the workers are initialized, see Prewarm
+	prespawn      int
+
+	firstErr     error
+	recentErrs   [16]error // the last errors, in a ring buffer, see Handler
+	nerrs        int       // the number of errors so far
+	stats        Stats
+	goroutines   atomic.Int64        // the goroutines of the pool running, see GoroutineCount
+	bufferHits   atomic.Int64        // the buffers reused by BufferFrom
+	bufferMisses atomic.Int64        // the buffers allocated by BufferFrom
+	running      map[*task]time.Time // the tasks running and their start time
+	waits        samples
+	errs         ring[error] // the errors collected instead of being sent to Errors, see WithErrorCollector
+	sending      int         // the number of workers blocked sending to Errors
+	sent         int         // the number of errors sent to Errors so far
+	onError      []func(error)
+	errMu        sync.Mutex  // serializes the deliveries of the errors, see OnError
+	spill        ring[error] // the errors waiting for room in Errors, see OverflowSpill
+	spilling     bool        // whether spillLoop is running
+	spillers     sync.WaitGroup
+
+	submissions uint64            // the number of tasks submitted so far
+	quota       int               // the cap of submissions, negative without one, see Quota
+	orders      uint64            // the number of tasks ordered so far, see WithOrdered
+	nextOut     uint64            // the order of the next task whose outcome must be delivered
+	outbox      map[uint64]func() // the deliveries waiting for the ones of the tasks submitted before
+	delivering  bool              // whether a worker is busy delivering outcomes in order
+
+	panicHandler  func(any)
+	panicPolicy   PanicPolicy
+	crashHandler  func(label, class string, value any) PanicPolicy
+	failFast      bool
+	maxErrors     int
+	attempts      int
+	backoff       Backoff
+	rateLimiter   *rateLimiter
+	limiters      []Limiter
+	submitLimiter *rateLimiter
+	onComplete    func(TaskInfo)
+	metrics       Metrics
+	autoscale     *autoscaler
+	aimd          *aimd
+	lowPriority   *niceness
+	idleTimeout   time.Duration
+	taskTimeout   time.Duration
+	escalation    *Escalation
+	logger        *slog.Logger
+	tracer        Tracer
+	clock         Clock
+	middlewares   []Middleware
+	breaker       *breaker
+
+	waitThreshold  time.Duration
+	onSlowWait     func(label string, wait time.Duration)
+	slowThreshold  time.Duration
+	onSlowTask     func(label string, elapsed time.Duration)
+	errorBuffer    int
+	errorOverflow  Overflow
+	collectErrors  bool
+	doneSentinel   bool
+	errorFilter    func(error) error
+	watchdog       *watchdog
+	heartbeat      *heartbeat
+	allocStats     bool
+	lockOSThread   bool
+	preemption     bool
+	profilerLabels bool
+	singleThreaded bool
+	submitTrace    bool
+	onEvent        func(Event)
+	ordered        bool
+	recording      *Recording
+	replay         []uint64 // the sequence numbers of the tasks to start next, see WithReplay
+
+	workerInit     func(workerID int) (any, error)
+	workerTeardown func(workerID int, state any)
+}
+
+// New returns a new pool where a limited number (concurrency) of goroutine can work at the same time.
+// Its optional behaviors are configured with options, e.g. cc.New(4, cc.WithQueueSize(16), cc.WithRetry(3, nil)).
+func New(concurrency int, opts ...Option) *Pool {
+	return NewWithContext(context.Background(), concurrency, opts...)
 }
 
-// New returns a new pool where a limited number (concurrency) of goroutine can work at the same time
-func New(concurrency int) *Pool {
-	wg := sync.WaitGroup{}
-	p := Pool{
-		Errors: make(chan error),
+// NewWithContext returns a new pool bound to ctx. When ctx is cancelled the functions still waiting for a worker are skipped,
+// and the ones already running under RunCtx see the cancellation through their context.
+func NewWithContext(ctx context.Context, concurrency int, opts ...Option) *Pool {
+	return newPool(ctx, concurrency, 0, opts)
+}
 
-		semaphore: make(chan bool, concurrency),
-		wg:        &wg,
+// newPool creates the pool and starts its concurrency workers
+func newPool(ctx context.Context, concurrency, queueSize int, opts []Option) *Pool {
+	p := &Pool{
+		queueSize: queueSize,
+		quota:     -1,
+		finished:  make(chan struct{}),
+		running:   map[*task]time.Time{},
+		clock:     realClock{},
+		outbox:    map[uint64]func(){},
+		keys:      map[string]*keyState{},
+		flights:   map[string]*Task[any]{},
+		memos:     map[string]*memo{},
+		tags:      map[string]*tagState{},
+		jobs:      map[*task]struct{}{},
+	}
+	p.ctx = ctx // until replaced by WithContext
+	p.notEmpty = sync.NewCond(&p.mu)
+	p.notFull = sync.NewCond(&p.mu)
+	p.slotFree = sync.NewCond(&p.mu)
+	for _, opt := range opts {
+		opt(p)
 	}
-	return &p
+	p.checkCompat()
+	p.ctx, p.cancel = context.WithCancelCause(p.ctx)
+	for _, l := range []*rateLimiter{p.rateLimiter, p.submitLimiter} {
+		if l != nil {
+			l.setClock(p.clock)
+		}
+	}
+	context.AfterFunc(p.ctx, p.wakeAll) // even paused, the workers skip the queued functions once cancelled
+	p.Errors = make(chan error, p.errorBuffer)
+	if p.watchdog != nil {
+		p.goTracked(p.watchdogLoop)
+	}
+	if p.heartbeat != nil && p.heartbeat.cancel {
+		p.goTracked(p.heartbeatLoop)
+	}
+	if p.autoscale != nil {
+		concurrency = min(max(concurrency, p.autoscale.min), p.autoscale.max)
+		p.goTracked(func() { p.controlLoop(p.autoscaleStep) })
+	}
+	if p.aimd != nil {
+		concurrency = min(max(concurrency, p.aimd.min), p.aimd.max)
+		p.goTracked(func() { p.controlLoop(p.aimdStep) })
+	}
+	if p.lowPriority != nil {
+		p.goTracked(func() { p.controlLoop(p.niceStep) })
+	}
+	p.Resize(concurrency)
+	if p.prespawn > 0 {
+		p.mu.Lock()
+		p.spawnIdle(p.prespawn)
+		p.mu.Unlock()
+	}
+	return p
+}
+
+// Name returns the name of the pool given with WithName
+func (p *Pool) Name() string {
+	return p.name
 }
 
 // Wait doesn't block, but ensures that the channels are closed when all the goroutines end.
+// After Wait the pool doesn't accept new functions anymore. Calling Wait again has no effect.
+// Wait, Stop and the Run variants are safe to call concurrently: a function submitted while the pool is closing
+// is either queued before it closed, and it runs before Errors is closed, or refused with ErrPoolClosed.
 func (p *Pool) Wait() {
-	go func() {
-		p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.advance(stateClosing) {
+		return
+	}
+	p.notEmpty.Broadcast()
+	p.notFull.Broadcast()
+	p.goTracked(func() {
+		<-p.idle()
+		p.workers.Wait()
+		p.spillers.Wait()
+		p.mu.Lock()
+		p.checkWorkersGone()
+		p.advance(stateShuttingDown)
+		onShutdown := p.onShutdown
+		p.mu.Unlock()
+		for _, fn := range onShutdown {
+			fn()
+		}
+		p.cancelWith(ErrShutdown, nil)
+		if p.doneSentinel && !p.collectErrors {
+			p.Errors <- ErrDone
+		}
 		close(p.Errors)
-		close(p.semaphore)
-	}()
-}
-
-// Run wraps the given function into a goroutine and ensure that the concurrency limits are respected.
-func (p *Pool) Run(fn func()) {
-	p.wg.Add(1)
-	go func() {
-		p.semaphore <- true
-		defer func() {
-			<-p.semaphore
-			p.wg.Done()
-		}()
-		fn()
-	}()
+		for _, c := range p.closers {
+			c()
+		}
+		p.mu.Lock()
+		p.advance(stateOver)
+		p.mu.Unlock()
+		close(p.finished)
+	})
+}
+
+// WaitErr calls Wait and blocks until all the functions end, collecting the errors sent to Errors meanwhile.
+// It returns them joined with errors.Join, or nil if there was none. Nobody else must consume Errors when using WaitErr.
+// With WithErrorCollector it returns the errors collected instead.
+func (p *Pool) WaitErr() error {
+	p.Wait()
+	var errs []error
+	for err := range p.Errors {
+		if err != ErrDone {
+			errs = append(errs, err)
+		}
+	}
+	if p.collectErrors {
+		errs = p.Errs()
+	}
+	return errors.Join(errs...)
+}
+
+// Run queues the given function for the workers of the pool, that ensure the concurrency limits are respected.
+// It returns ErrPoolClosed if Wait or Stop was called already, and ErrQuotaExceeded if the pool used up its Quota.
+// The options configure fn, e.g. with its metadata, see Meta.
+func (p *Pool) Run(fn func(), opts ...TaskOption) error {
+	return p.push(plainTask(fn).with(opts))
+}
+
+// RunCtx is like Run, but fn receives a context that is cancelled when either ctx or the pool context is done.
+// If that happens before a worker picks fn up, fn is skipped. Non-nil errors returned by fn are sent to Errors.
+func (p *Pool) RunCtx(ctx context.Context, fn func(ctx context.Context) error, opts ...TaskOption) error {
+	return p.push((&task{ctx: ctx, fn: fn}).with(opts))
+}
+
+// RunNamed is like Run, but fn is identified by label in the errors, stats and panics coming from it.
+func (p *Pool) RunNamed(label string, fn func()) error {
+	t := plainTask(fn)
+	t.label = label
+	return p.push(t)
+}
+
+// TryRun is like Run, but it queues fn only if it can start right away, or if there is room in the queue of a pool
+// created with NewBounded. It returns false otherwise, so that best-effort work can be dropped under load.
+func (p *Pool) TryRun(fn func()) bool {
+	return p.tryPush(plainTask(fn))
+}
+
+// RunWithCleanup is like Go, but cleanup is called exactly once when fn is over, whether it returned, panicked or was
+// skipped because its context was done before it started. It's called from the worker, before the error of fn is sent
+// to Errors, or right away if fn is refused because the pool is closed.
+func (p *Pool) RunWithCleanup(fn func() error, cleanup func()) error {
+	err := p.push(&task{cleanup: cleanup, fn: func(context.Context) error {
+		return fn()
+	}})
+	if err != nil {
+		cleanup()
+	}
+	return err
+}
+
+// RunCtxWithCleanup is like RunCtx, but cleanup is called exactly once: as soon as ctx or the pool context is done if
+// fn didn't start yet, without waiting for a worker to skip it, or else when fn is over, or right away if fn is refused
+// because the pool is closed. It suits the functions holding resources while queued, like temp files or locks.
+// When cleanup is called on cancellation it runs in its own goroutine, and fn doesn't run anymore.
+func (p *Pool) RunCtxWithCleanup(ctx context.Context, fn func(ctx context.Context) error, cleanup func()) error {
+	var once sync.Once
+	release := func() { once.Do(cleanup) }
+	stopTask := context.AfterFunc(ctx, release)
+	stopPool := context.AfterFunc(p.ctx, release)
+	err := p.push(&task{ctx: ctx, cleanup: release, fn: func(ctx context.Context) error {
+		task, pool := stopTask(), stopPool()
+		if !task || !pool {
+			return nil // released already
+		}
+		return fn(ctx)
+	}})
+	if err != nil {
+		stopTask()
+		stopPool()
+		release()
+	}
+	return err
+}
+
+// RunKeyed is like Run, but the functions submitted with the same key run one at a time, or up to the limit set with
+// WithPerKeyLimit, in submission order, while functions with different keys run in parallel within the concurrency of
+// the pool.
+func (p *Pool) RunKeyed(key string, fn func()) error {
+	t := plainTask(fn)
+	t.keyed, t.key = true, key
+	return p.push(t)
+}
+
+// RunPriority is like Run, but fn is queued ahead of all the functions with a lower priority, so that it's the next to run
+// when a worker frees up. Functions run by Run have priority 0, functions with the same priority run in submission order.
+func (p *Pool) RunPriority(priority int, fn func()) error {
+	t := plainTask(fn)
+	t.priority = priority
+	return p.push(t)
+}
+
+// Go runs fn like Run does, and sends the error it returns to Errors unless it's nil.
+func (p *Pool) Go(fn func() error, opts ...TaskOption) error {
+	return p.push((&task{fn: func(context.Context) error {
+		return fn()
+	}}).with(opts))
 }
@@ -0,0 +1,100 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// peak tracks the highest number of functions running at the same time
+type peak struct {
+	mu        sync.Mutex
+	cur, high int
+}
+
+func (p *peak) run(d time.Duration) {
+	p.mu.Lock()
+	p.cur++
+	p.high = max(p.high, p.cur)
+	p.mu.Unlock()
+	time.Sleep(d)
+	p.mu.Lock()
+	p.cur--
+	p.mu.Unlock()
+}
+
+// within fails the test if fn is still blocked after d
+func within(t *testing.T, d time.Duration, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(d):
+		t.Fatalf("still blocked after %s", d)
+	}
+}
+
+// drain collects the errors of p until Errors is closed
+func drain(p *Pool) func() []error {
+	var errs []error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for err := range p.Errors {
+			errs = append(errs, err)
+		}
+	}()
+	return func() []error {
+		<-done
+		return errs
+	}
+}
+
+func TestRunConcurrency(t *testing.T) {
+	p := New(3)
+	errs := drain(p)
+	var pk peak
+	var ran atomic.Int32
+	for range 20 {
+		p.Run(func() { pk.run(time.Millisecond); ran.Add(1) })
+	}
+	p.Wait()
+	within(t, 5*time.Second, func() { errs() })
+	if ran.Load() != 20 {
+		t.Errorf("ran %d functions, want 20", ran.Load())
+	}
+	if pk.high != 3 {
+		t.Errorf("%d functions ran at the same time, want 3", pk.high)
+	}
+}
+
+func TestRunCtxCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewWithContext(ctx, 1)
+	errs := drain(p)
+	started := make(chan struct{})
+	p.RunCtx(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	var skipped atomic.Bool
+	p.RunCtx(context.Background(), func(context.Context) error { skipped.Store(true); return nil })
+	cancel()
+	p.Wait()
+	got := errs()
+	if len(got) == 0 || !errors.Is(got[0], context.Canceled) {
+		t.Errorf("got errors %v, want the cancellation of the running function", got)
+	}
+	if skipped.Load() {
+		t.Error("the function waiting for a slot ran after the pool context was cancelled")
+	}
+}
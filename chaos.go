@@ -0,0 +1,72 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrChaos is the error of the runs failed on purpose by a pool created with WithChaos
+var ErrChaos = errors.New("cc: chaos")
+
+// ChaosConfig configures WithChaos
+type ChaosConfig struct {
+	MaxDelay  time.Duration // the longest delay injected before a run
+	DelayRate float64       // the probability of delaying a run, between 0 and 1
+	ErrorRate float64       // the probability of failing a run with ErrChaos instead of running the function
+	Reorder   bool          // whether the queued functions start in random order, see ScheduleRandom
+	Seed      uint64        // the seed of the random choices, to reproduce a run
+}
+
+// WithChaos makes the pool delay and fail some runs of the functions at random, and start them out of order, so that
+// applications can test their resilience to the variability of the pool without changing their functions. The delays
+// are waited for after the function is picked up by a worker, holding it, and are cut short if the context of the
+// function is done. A run failed with ErrChaos is retried like any other failure, see WithRetry.
+func WithChaos(cfg ChaosConfig) Option {
+	return func(p *Pool) {
+		c := &chaos{pool: p, cfg: cfg, rnd: rand.New(rand.NewPCG(cfg.Seed, cfg.Seed>>32))}
+		p.middlewares = append(p.middlewares, c)
+		if cfg.Reorder {
+			p.queue.schedule = ScheduleRandom
+		}
+	}
+}
+
+// chaos is the middleware injecting the faults of WithChaos
+type chaos struct {
+	pool *Pool
+	cfg  ChaosConfig
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func (c *chaos) Before(ctx context.Context, _ TaskInfo) (context.Context, error) {
+	c.mu.Lock()
+	var delay time.Duration
+	if c.cfg.MaxDelay > 0 && c.rnd.Float64() < c.cfg.DelayRate {
+		delay = time.Duration(c.rnd.Int64N(int64(c.cfg.MaxDelay)))
+	}
+	fail := c.rnd.Float64() < c.cfg.ErrorRate
+	c.mu.Unlock()
+
+	if delay > 0 {
+		ready := make(chan struct{})
+		timer := c.pool.clock.AfterFunc(delay, func() { close(ready) })
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+	if fail {
+		return ctx, ErrChaos
+	}
+	return ctx, nil
+}
+
+func (c *chaos) After(_ context.Context, _ TaskInfo, err error) error {
+	return err
+}
@@ -0,0 +1,73 @@
+package cc
+
+import "context"
+
+// Child returns a new pool running at most maxShare functions at the same time, whose functions also take their slots
+// out of the concurrency of p: p and all its children together never run more functions than the concurrency of p.
+// The child has its own Errors and must be waited on like any pool. It's bound to the context of p, and p doesn't
+// finish before the child does: Wait on p returns once the children are done too. The child of a closed pool is closed.
+func (p *Pool) Child(maxShare int, opts ...Option) *Pool {
+	c := newPool(p.ctx, maxShare, 0, opts)
+	c.parent = p
+	p.mu.Lock()
+	closed := p.state != stateOpen
+	if !closed {
+		p.pending++
+		c.closers = append(c.closers, func() { p.done(nil) })
+	}
+	p.mu.Unlock()
+	if closed {
+		c.Wait()
+	}
+	return c
+}
+
+// acquire takes n slots, capped to the concurrency, from p and its ancestors, blocking until they are free.
+// It returns a function giving them back, or the error of ctx if it's done first.
+func (p *Pool) acquire(ctx context.Context, n int) (func(), error) {
+	releaseParent := func() {}
+	if p.parent != nil {
+		var err error
+		if releaseParent, err = p.parent.acquire(ctx, n); err != nil {
+			return nil, err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		p.slotFree.Broadcast()
+		p.mu.Unlock()
+	})
+	defer stop()
+	n = min(n, p.concurrency)
+	for p.used+n > p.concurrency {
+		if ctx.Err() != nil {
+			releaseParent()
+			return nil, ctx.Err()
+		}
+		p.slotWaiters++
+		p.slotFree.Wait()
+		p.slotWaiters--
+	}
+	p.used += n
+	return func() {
+		p.releaseUnits(n)
+		releaseParent()
+	}, nil
+}
+
+// releaseUnits frees n slots, waking up a worker or a child pool waiting for them
+func (p *Pool) releaseUnits(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.used -= n
+	p.relieved()
+	if p.runnable() {
+		p.notEmpty.Signal()
+	}
+	if p.slotWaiters > 0 {
+		p.slotFree.Broadcast()
+	}
+}
@@ -0,0 +1,50 @@
+package cc
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChildSharesConcurrency(t *testing.T) {
+	p := New(3)
+	a, b := p.Child(2), p.Child(2)
+	var pk peak
+	var ran atomic.Int32
+	for range 20 {
+		a.Run(func() { pk.run(time.Millisecond); ran.Add(1) })
+		b.Run(func() { pk.run(time.Millisecond); ran.Add(1) })
+	}
+	for _, c := range []*Pool{a, b} {
+		c.Wait()
+		go func() {
+			for range c.Errors {
+			}
+		}()
+	}
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	if ran.Load() != 40 {
+		t.Errorf("the parent ended after %d functions of its children out of 40", ran.Load())
+	}
+	if pk.high > 3 {
+		t.Errorf("%d functions of the children ran at the same time, want at most 3", pk.high)
+	}
+}
+
+func TestChildMaxShare(t *testing.T) {
+	p := New(4)
+	c := p.Child(2)
+	var pk peak
+	for range 10 {
+		c.Run(func() { pk.run(time.Millisecond) })
+	}
+	if err := c.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	p.WaitErr()
+	if pk.high != 2 {
+		t.Errorf("%d functions of the child ran at the same time, want 2", pk.high)
+	}
+}
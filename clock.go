@@ -0,0 +1,182 @@
+package cc
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock tells the time to a pool, for its timestamps, delays, retries, timeouts, idle timers and rate limits, so that
+// tests can control it with a FakeClock. Implementations must be safe for concurrent use.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls fn once d has elapsed, unless the returned Timer is stopped first. fn must be called without
+	// holding the locks of the clock, as it may arm a new timer
+	AfterFunc(d time.Duration, fn func()) Timer
+}
+
+// Timer is a timer started by a Clock
+type Timer interface {
+	// Stop prevents the timer from firing, and reports whether it did
+	Stop() bool
+}
+
+// WithClock makes the pool tell the time with c instead of the system clock
+func WithClock(c Clock) Option {
+	return func(p *Pool) {
+		p.clock = c
+	}
+}
+
+// realClock is the system clock
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, fn func()) Timer {
+	return time.AfterFunc(d, fn)
+}
+
+// since returns the time elapsed since t according to the clock of the pool
+func (p *Pool) since(t time.Time) time.Duration {
+	return p.clock.Now().Sub(t)
+}
+
+// tick returns a channel receiving the time every d according to c, dropping the ticks for slow receivers like
+// time.Ticker does, and a function stopping it
+func tick(c Clock, d time.Duration) (<-chan time.Time, func()) {
+	ch := make(chan time.Time, 1)
+	var mu sync.Mutex
+	var timer Timer
+	stopped := false
+	next := c.Now()
+	var arm func()
+	arm = func() {
+		next = next.Add(d)
+		timer = c.AfterFunc(next.Sub(c.Now()), func() {
+			select {
+			case ch <- c.Now():
+			default:
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !stopped {
+				arm()
+			}
+		})
+	}
+	mu.Lock()
+	arm()
+	mu.Unlock()
+	return ch, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		timer.Stop()
+	}
+}
+
+// withClockTimeout returns a copy of ctx that expires after d according to c, see FakeClock
+func withClockTimeout(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	return withClockTimeoutCause(ctx, c, d, context.DeadlineExceeded)
+}
+
+// withClockTimeoutCause is like withClockTimeout, the cause of the expiration being cause
+func withClockTimeoutCause(ctx context.Context, c Clock, d time.Duration, cause error) (context.Context, context.CancelFunc) {
+	if _, ok := c.(realClock); ok {
+		return context.WithTimeoutCause(ctx, d, cause)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := c.AfterFunc(d, func() { cancel(cause) })
+	return ctx, func() {
+		timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// FakeClock is a Clock whose time only moves when told to, for tests. Its timers fire synchronously, from the
+// goroutine moving the time, in the order of their deadline. The contexts it times out, see RunWithTimeout, have no deadline and
+// report context.Canceled as their error, with a cause wrapping context.DeadlineExceeded.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock set at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	fn    func()
+}
+
+// Now returns the time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc calls fn once the time of the clock has moved by d
+func (c *FakeClock) AfterFunc(d time.Duration, fn func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), fn: fn}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Timers returns the number of timers waiting to fire, so that tests can wait for the pool to arm one before
+// moving the time, e.g. for a retry
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// Advance moves the time of the clock by d, firing the timers whose deadline is reached on the way, including the
+// ones armed by the timers fired
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	for {
+		i := -1
+		for j, t := range c.timers {
+			if !t.at.After(end) && (i < 0 || t.at.Before(c.timers[i].at)) {
+				i = j
+			}
+		}
+		if i < 0 {
+			break
+		}
+		t := c.timers[i]
+		c.timers = slices.Delete(c.timers, i, i+1)
+		if t.at.After(c.now) {
+			c.now = t.at
+		}
+		c.mu.Unlock()
+		t.fn()
+		c.mu.Lock()
+	}
+	c.now = end
+	c.mu.Unlock()
+}
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := slices.Index(c.timers, t)
+	if i < 0 {
+		return false
+	}
+	c.timers = slices.Delete(c.timers, i, i+1)
+	return true
+}
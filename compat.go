@@ -0,0 +1,89 @@
+package cc
+
+import "fmt"
+
+// Version is a version of the semantics of the pools, see Compat
+type Version int
+
+const (
+	// V1 is the semantics of the first releases: a goroutine started for each function, waiting for its turn, so that
+	// Run never blocks, and an unbuffered Errors. Wait doesn't block in either version.
+	V1 Version = 1
+	// V2 is the semantics of the current engine, the default: the functions are queued for workers started on demand
+	V2 Version = 2
+)
+
+// Compat makes the pool behave as in version v, so that the programs written for it can upgrade without changes and
+// opt into the new engine when ready. With V1 each function queued gets a goroutine of its own, waiting for a slot of
+// the pool and exiting once the function is done; the queue is unbounded and Errors unbuffered, and fail-fast is off.
+// Everything else applies as with V2: keys, classes, circuits, priorities, limits, retries and the like. The options
+// given after Compat still apply, except for the ones that need long-lived workers or a bounded queue: WithQueueSize,
+// WithPrespawn and NewSerial make New panic under V1. The functions given to WithWorkerInit and WithWorkerTeardown are
+// called for each function, every goroutine being a worker of its own. Submitting a function after Wait fails with
+// ErrPoolClosed in both versions, where the first releases raced with the closing of Errors.
+func Compat(v Version) Option {
+	return func(p *Pool) {
+		p.compat = v
+		if v == V1 {
+			p.queueSize = 0
+			p.errorBuffer = 0
+			p.failFast = false
+		}
+	}
+}
+
+// checkCompat panics if the options of the pool are incompatible with its Version, see Compat
+func (p *Pool) checkCompat() {
+	if p.compat != V1 {
+		return
+	}
+	for option, set := range map[string]bool{
+		"WithQueueSize": p.queueSize > 0,
+		"WithPrespawn":  p.prespawn > 0,
+		"NewSerial":     p.serial,
+	} {
+		if set {
+			panic(fmt.Sprintf("cc: %s is incompatible with Compat(V1)", option))
+		}
+	}
+}
+
+// spawnLegacy starts a goroutine for each queued task that has none waiting for it yet, see Compat. It must be called
+// with p.mu held.
+func (p *Pool) spawnLegacy() {
+	for p.queue.Len() > p.legacyWaiting {
+		p.workers.Add(1)
+		if !p.tryGo(p.legacyWorker) {
+			p.workers.Done()
+			return
+		}
+		p.legacyWaiting++
+	}
+}
+
+// legacyWorker runs a task of the queue once a slot is free, in a goroutine of its own, see Compat. The task isn't
+// necessarily the one it was started for, but the next one as the workers would pick it up.
+func (p *Pool) legacyWorker() {
+	defer p.workers.Done()
+	p.mu.Lock()
+	for !p.runnable() && !(p.state != stateOpen && p.pending == 0) && p.legacyWaiting <= p.queue.Len() {
+		p.notEmpty.Wait()
+	}
+	p.legacyWaiting--
+	if !p.runnable() {
+		// nothing left to run, or a queued task was removed from the queue without running
+		p.mu.Unlock()
+		return
+	}
+	t := p.claim()
+	id := p.takeWorkerID()
+	p.mu.Unlock()
+
+	w := p.initWorker(id)
+	units := t.units // t may be recycled once executed
+	w.current = t
+	p.execute(w, t)
+	w.current = nil
+	p.teardownWorker(w)
+	p.releaseUnits(units)
+}
@@ -0,0 +1,73 @@
+package cc
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompatV1GoroutinePerFunction(t *testing.T) {
+	p := New(2, Compat(V1))
+	release := make(chan struct{})
+	var pk peak
+	within(t, 5*time.Second, func() {
+		for range 10 {
+			p.Run(func() { <-release; pk.run(time.Millisecond) }) // never blocking, each function waiting for a slot
+		}
+	})
+	if n := p.GoroutineCount(); n != 10 {
+		t.Errorf("%d goroutines for 10 functions", n)
+	}
+	close(release)
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	if pk.high != 2 {
+		t.Errorf("%d functions ran at the same time, want 2", pk.high)
+	}
+}
+
+func TestCompatV1Keyed(t *testing.T) {
+	p := New(4, Compat(V1))
+	var pk peak
+	var ran atomic.Int32
+	for range 20 {
+		if err := p.RunKeyed("k", func() { pk.run(time.Millisecond); ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	if ran.Load() != 20 {
+		t.Errorf("ran %d functions, want 20", ran.Load())
+	}
+	if pk.high != 1 {
+		t.Errorf("%d functions with the same key ran at the same time, want 1", pk.high)
+	}
+}
+
+func TestCompatV1Class(t *testing.T) {
+	p := New(4, Compat(V1), WithLimits(map[string]int{"disk": 1}))
+	var pk peak
+	for range 20 {
+		if err := p.RunClass("disk", func() { pk.run(time.Millisecond) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	if pk.high != 1 {
+		t.Errorf("%d functions of the class ran at the same time, want 1", pk.high)
+	}
+}
+
+func TestCompatV1Incompatible(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("WithQueueSize didn't panic under Compat(V1)")
+		}
+	}()
+	New(2, Compat(V1), WithQueueSize(4))
+}
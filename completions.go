@@ -0,0 +1,37 @@
+package cc
+
+import "time"
+
+// Completion records a function that ended, see WithCompletions
+type Completion struct {
+	Label    string
+	Queued   time.Time // when the function was queued for its last attempt, when it was submitted unless retried
+	Started  time.Time // when the last attempt started
+	Finished time.Time // when the last attempt ended
+	Err      error     // the error returned by the last attempt
+}
+
+// WithCompletions makes the pool send a Completion on Completions for each function that ran, in completion order,
+// once it's done retrying, e.g. to analyze the latencies of a batch or to draw it as a Gantt chart. The skipped
+// functions are left out. Completions gets a buffer of n completions, and must be consumed like Errors: it is closed
+// by Wait once all the functions ended.
+func WithCompletions(n int) Option {
+	return func(p *Pool) {
+		p.Completions = make(chan Completion, n)
+		p.closers = append(p.closers, func() { close(p.Completions) })
+	}
+}
+
+// completed sends the Completion of t to Completions, if enabled
+func (p *Pool) completed(t *task, info TaskInfo) {
+	if p.Completions == nil {
+		return
+	}
+	p.Completions <- Completion{
+		Label:    info.Label,
+		Queued:   t.queued,
+		Started:  info.Start,
+		Finished: info.Start.Add(info.Duration),
+		Err:      info.Err,
+	}
+}
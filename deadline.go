@@ -0,0 +1,25 @@
+package cc
+
+import (
+	"context"
+	"time"
+)
+
+// DeadlineSetter is implemented by the connections and files whose blocking reads and writes can be interrupted
+// with a deadline, like net.Conn and os.File
+type DeadlineSetter interface {
+	SetDeadline(t time.Time) error
+}
+
+// BindDeadline ties d to ctx, typically the context of a function run by a pool, so that the cancellation of ctx also
+// unblocks the reads and writes of d, that fail with a timeout error: the deadline of d is set to the deadline of ctx
+// if it has one, and to now when ctx is done. The returned function unties them, returning false if ctx was done
+// already; it must be called before d is reused by other code.
+func BindDeadline(ctx context.Context, d DeadlineSetter) (stop func() bool) {
+	if deadline, ok := ctx.Deadline(); ok {
+		d.SetDeadline(deadline)
+	}
+	return context.AfterFunc(ctx, func() {
+		d.SetDeadline(time.Now())
+	})
+}
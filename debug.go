@@ -0,0 +1,71 @@
+package cc
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// debugState is the state of a pool rendered by Handler
+type debugState struct {
+	Name    string      `json:"name"`
+	Stats   Stats       `json:"stats"`
+	Running []debugTask `json:"running"`
+	Errors  []string    `json:"recent_errors"`
+	Closed  bool        `json:"closed"`
+	Paused  bool        `json:"paused"`
+}
+
+type debugTask struct {
+	Label   string        `json:"label"`
+	Start   time.Time     `json:"start"`
+	Elapsed time.Duration `json:"elapsed_ns"`
+}
+
+var debugPage = template.Must(template.New("pool").Parse(`<!DOCTYPE html>
+<html><head><title>pool {{.Name}}</title></head><body>
+<h1>pool {{.Name}}</h1>
+<p>{{.Stats.Running}} running, {{.Stats.Queued}} queued, {{.Stats.Completed}} completed, {{.Stats.Failed}} failed,
+{{.Stats.Skipped}} skipped{{if .Paused}}, paused{{end}}{{if .Closed}}, closed{{end}}</p>
+<h2>Running</h2>
+<table>{{range .Running}}<tr><td>{{.Label}}</td><td>{{.Elapsed}}</td></tr>{{end}}</table>
+<h2>Recent errors</h2>
+<ul>{{range .Errors}}<li>{{.}}</li>{{end}}</ul>
+</body></html>
+`))
+
+// Handler returns an HTTP handler rendering the stats, the running functions and the recent errors of the pool,
+// to be mounted e.g. under /debug/ccpool. It renders JSON, or HTML for the clients accepting text/html like browsers.
+func (p *Pool) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := p.debugState()
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			debugPage.Execute(w, state)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(state)
+	})
+}
+
+func (p *Pool) debugState() debugState {
+	state := debugState{Name: p.name, Stats: p.Stats(), Running: []debugTask{}, Errors: []string{}}
+	now := p.clock.Now()
+	for _, t := range p.runningTasks() {
+		state.Running = append(state.Running, debugTask{Label: t.Label, Start: t.Start, Elapsed: now.Sub(t.Start)})
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state.Closed, state.Paused = p.state != stateOpen, p.paused
+	n := min(p.nerrs, len(p.recentErrs))
+	for i := range n {
+		// the most recent first
+		state.Errors = append(state.Errors, p.recentErrs[(p.nerrs-1-i)%len(p.recentErrs)].Error())
+	}
+	return state
+}
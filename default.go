@@ -0,0 +1,39 @@
+package cc
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+var (
+	defaultMu   sync.Mutex
+	defaultPool *Pool
+)
+
+// Go runs fn in the default pool, a pool of GOMAXPROCS workers shared by the whole program and created on first use.
+// The errors returned by fn are collected until WaitAll, so nothing needs to consume them meanwhile.
+func Go(fn func() error) error {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultPool == nil {
+		defaultPool = New(runtime.GOMAXPROCS(0), WithName("default"), WithErrorCollector())
+	}
+	return defaultPool.Go(fn)
+}
+
+// WaitAll blocks until all the functions run by Go end, and returns their errors joined with errors.Join, or nil if
+// there was none. The default pool is reset: the functions run by Go afterwards belong to a new batch, which makes
+// WaitAll suitable to isolate tests from each other.
+func WaitAll() error {
+	defaultMu.Lock()
+	p := defaultPool
+	defaultPool = nil
+	defaultMu.Unlock()
+	if p == nil {
+		return nil
+	}
+	p.Wait()
+	<-p.finished
+	return errors.Join(p.Errs()...)
+}
@@ -0,0 +1,39 @@
+package cc
+
+import (
+	"context"
+	"time"
+)
+
+// RunAfter queues fn once d has elapsed, and returns a handle on it like Submit does. Until then fn takes no spot in
+// the queue nor a worker, but it counts as pending: Wait doesn't close the pool before it ends. Canceling the handle
+// before d has elapsed drops fn right away, with ErrCanceled.
+func (p *Pool) RunAfter(d time.Duration, fn func()) *Task[struct{}] {
+	t := newTask(p, func(context.Context) (struct{}, error) {
+		fn()
+		return struct{}{}, nil
+	})
+	if err := p.pushLater(t.task, d); err != nil {
+		t.task.onDone(err)
+	}
+	return t
+}
+
+// RunAt is like RunAfter, queueing fn at the given time
+func (p *Pool) RunAt(at time.Time, fn func()) *Task[struct{}] {
+	return p.RunAfter(at.Sub(p.clock.Now()), fn)
+}
+
+// pushLater queues t after d, regardless of the size of the queue. It returns ErrPoolClosed if the pool is closed, or ErrQuotaExceeded.
+func (p *Pool) pushLater(t *task, d time.Duration) error {
+	p.mu.Lock()
+	if err := p.refuse(); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	p.reserve(t)
+	t.timer = p.clock.AfterFunc(d, func() { p.requeue(t) })
+	p.mu.Unlock()
+	p.emitSubmitted(t.label, t.submitted)
+	return nil
+}
@@ -0,0 +1,80 @@
+package cc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Dump writes a report of the activity of the pool to w, for debugging a pool that doesn't finish: the number of
+// functions queued, and for each running function its label, how long it has been running and the stack trace of the
+// goroutine running it.
+func (p *Pool) Dump(w io.Writer) error {
+	running := p.runningTasks()
+	stats := p.Stats()
+	stacks := goroutineStacks()
+
+	var b bytes.Buffer
+	name := p.name
+	if name == "" {
+		name = "(unnamed)"
+	}
+	fmt.Fprintf(&b, "pool %s: %d running, %d queued\n", name, len(running), stats.Queued)
+	now := p.clock.Now()
+	for _, t := range running {
+		label := t.Label
+		if label == "" {
+			label = "(unlabeled)"
+		}
+		fmt.Fprintf(&b, "\n%s, running for %s\n", label, now.Sub(t.Start).Round(time.Millisecond))
+		if stack, ok := stacks[t.goroutine]; ok {
+			b.Write(stack)
+			b.WriteByte('\n')
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// goroutineStacks returns the stack traces of all the goroutines, by goroutine ID
+func goroutineStacks() map[uint64][]byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := map[uint64][]byte{}
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if id := goroutineID(stack); id != 0 {
+			stacks[id] = stack
+		}
+	}
+	return stacks
+}
+
+// goroutineID parses the ID of a goroutine from the header of its stack trace, "goroutine 42 [running]:"
+func goroutineID(stack []byte) uint64 {
+	stack, ok := bytes.CutPrefix(stack, []byte("goroutine "))
+	if !ok {
+		return 0
+	}
+	i := bytes.IndexByte(stack, ' ')
+	if i < 0 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(stack[:i]), 10, 64)
+	return id
+}
+
+// currentGoroutineID returns the ID of the calling goroutine
+func currentGoroutineID() uint64 {
+	buf := make([]byte, 64)
+	return goroutineID(buf[:runtime.Stack(buf, false)])
+}
@@ -0,0 +1,73 @@
+package cc
+
+import (
+	"context"
+	"sync"
+)
+
+// ErrGroup runs functions in a pool with the semantics of golang.org/x/sync/errgroup, so that code written against
+// errgroup can adopt the limits, metrics and panic handling of the pool by replacing errgroup.WithContext with
+// Pool.Group. Panics are returned by Wait as a PanicError rather than propagated.
+type ErrGroup struct {
+	pool   *Pool
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+// Group returns a new ErrGroup running its functions in p, and a context derived from ctx that is cancelled when
+// a function of the group fails or Wait returns, like errgroup.WithContext does.
+func (p *Pool) Group(ctx context.Context) (*ErrGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &ErrGroup{pool: p, cancel: cancel}, ctx
+}
+
+// Go queues fn in the pool. The first function returning an error cancels the context of the group, and its error
+// is returned by Wait.
+func (g *ErrGroup) Go(fn func() error) {
+	g.wg.Add(1)
+	if err := g.pool.push(g.task(fn)); err != nil {
+		g.done(err)
+	}
+}
+
+// TryGo is like Go, but it queues fn only if it can start right away, like TryRun does, and reports whether it did
+func (g *ErrGroup) TryGo(fn func() error) bool {
+	g.wg.Add(1)
+	if !g.pool.tryPush(g.task(fn)) {
+		g.wg.Done()
+		return false
+	}
+	return true
+}
+
+// Wait blocks until all the functions of the group end, and returns the first error, if any
+func (g *ErrGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel(g.err)
+	return g.err
+}
+
+func (g *ErrGroup) task(fn func() error) *task {
+	return &task{
+		fn: func(context.Context) error {
+			return fn()
+		},
+		onDone: g.done,
+	}
+}
+
+// done records the outcome of a function of the group, unwrapping its error for callers comparing it with ==
+func (g *ErrGroup) done(err error) {
+	if te, ok := err.(*TaskError); ok {
+		err = te.Err
+	}
+	if err != nil {
+		g.once.Do(func() {
+			g.err = err
+			g.cancel(err)
+		})
+	}
+	g.wg.Done()
+}
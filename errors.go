@@ -0,0 +1,106 @@
+package cc
+
+// TaskError is the error sent to Errors when a function fails. It describes the run of the function, and
+// wraps the error it returned for errors.Is and errors.As.
+type TaskError struct {
+	TaskInfo
+}
+
+func (e *TaskError) Error() string {
+	if p := provenance(e.Pool, e.Label); p != "" {
+		return "cc:" + p + ": " + e.Err.Error()
+	}
+	return e.Err.Error()
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// provenance formats the pool name and the task label for error messages
+func provenance(pool, task string) string {
+	switch {
+	case pool != "" && task != "":
+		return " in " + pool + "/" + task
+	case pool != "":
+		return " in " + pool
+	case task != "":
+		return " in " + task
+	}
+	return ""
+}
+
+// report records err, returned by a function of the pool, and sends it to Errors
+func (p *Pool) report(err error) {
+	p.failed(err)
+	p.send(err)
+}
+
+// send delivers err to the callbacks registered with OnError, then sends it to Errors or collects it with
+// WithErrorCollector. The deliveries are serialized, so that all the consumers see the errors in the same order.
+func (p *Pool) send(err error) {
+	p.mu.Lock()
+	handlers, collect := p.onError, p.collectErrors
+	if !collect {
+		p.sending++
+	}
+	p.mu.Unlock()
+
+	p.errMu.Lock()
+	defer p.errMu.Unlock()
+	for _, fn := range handlers {
+		fn(err)
+	}
+	if collect {
+		p.mu.Lock()
+		if !p.errs.push(err) {
+			p.stats.DroppedErrors++
+		}
+		p.mu.Unlock()
+		return
+	}
+
+	p.sendError(err)
+
+	p.mu.Lock()
+	p.sending--
+	p.sent++
+	p.mu.Unlock()
+}
+
+// OnError registers fn to receive the errors of the pool, in addition to Errors. The callbacks are called from the
+// worker of the failed function, one error at a time and in the order they were registered, before the error is sent
+// to Errors: every consumer sees the same errors in the same order. The callbacks must not block.
+func (p *Pool) OnError(fn func(error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onError = append(p.onError, fn)
+}
+
+// failed records err, returned by a function of the pool, stopping the pool if it's the first one and fail-fast is on
+func (p *Pool) failed(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recentErrs[p.nerrs%len(p.recentErrs)] = err
+	p.nerrs++
+	if p.firstErr == nil {
+		p.firstErr = err
+		if p.failFast {
+			p.cancelWith(ErrSiblingFailed, err)
+		}
+	}
+}
+
+// FirstError returns the first error reported by the functions of the pool, or nil if there was none so far.
+func (p *Pool) FirstError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.firstErr
+}
+
+// Errs returns the errors collected so far by a pool created with WithErrorCollector
+func (p *Pool) Errs() []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.errs.values()
+}
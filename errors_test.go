@@ -0,0 +1,39 @@
+package cc
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFailFast(t *testing.T) {
+	p := New(1, WithFailFast())
+	errs := drain(p)
+	failure := errors.New("failure")
+	var ran atomic.Int32
+	p.Go(func() error { return failure })
+	for range 10 {
+		p.Run(func() { ran.Add(1) })
+	}
+	p.Wait()
+	if got := errs(); len(got) != 1 || !errors.Is(got[0], failure) {
+		t.Errorf("got errors %v, want the first failure only", got)
+	}
+	if n := ran.Load(); n != 0 {
+		t.Errorf("%d functions ran after the first failure", n)
+	}
+	if err := p.FirstError(); !errors.Is(err, failure) {
+		t.Errorf("FirstError returned %v, want the first failure", err)
+	}
+}
+
+func TestFirstErrorNone(t *testing.T) {
+	p := New(2)
+	errs := drain(p)
+	p.Run(func() {})
+	p.Wait()
+	errs()
+	if err := p.FirstError(); err != nil {
+		t.Errorf("FirstError returned %v without failures", err)
+	}
+}
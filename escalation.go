@@ -0,0 +1,131 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrEscalated is the cause of the cancellation of a function that ran for too long, see WithEscalation
+var ErrEscalated = errors.New("cc: function cancelled for running too long")
+
+// ErrAbandoned is the error of a function abandoned by its worker for ignoring its cancellation, see WithEscalation
+var ErrAbandoned = errors.New("cc: function abandoned after ignoring its cancellation")
+
+// Escalation is how a pool deals with the functions running for too long, in stages counted from their start,
+// see WithEscalation. A zero duration skips its stage.
+type Escalation struct {
+	// Warn is when OnWarn is called, with the label of the function and how long it has been running
+	Warn   time.Duration
+	OnWarn func(label string, elapsed time.Duration)
+	// Cancel is when the context of the function is cancelled, with ErrEscalated as its cause
+	Cancel time.Duration
+	// Grace is how long after Cancel the function is abandoned if it's still running: its worker stops waiting for it
+	// and moves on, the function failing with ErrAbandoned, and OnAbandon is called with its label
+	Grace     time.Duration
+	OnAbandon func(label string)
+}
+
+// WithEscalation makes the pool escalate with the functions running for too long as e says: a warning, then the
+// cancellation of their context, then, for the functions ignoring it like some third-party code does, a replacement of
+// their worker. An abandoned function keeps running in its goroutine, that leaks until the function returns, its
+// outcome being ignored then, and its worker is replaced by a new one, leaving the state of WithWorkerInit to it.
+// To be abandoned, the functions of a pool with a Grace run in a goroutine of their own.
+func WithEscalation(e Escalation) Option {
+	return func(p *Pool) {
+		p.escalation = &e
+	}
+}
+
+// supervised runs t on the same terms as profiled, escalating if it runs for too long, see WithEscalation
+func (p *Pool) supervised(ctx context.Context, w *worker, t *task, start time.Time) error {
+	e := p.escalation
+	if e == nil {
+		return p.profiled(ctx, t, start)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	label := t.label
+	var timers []Timer
+	defer func() {
+		for _, timer := range timers {
+			timer.Stop()
+		}
+	}()
+	if e.Warn > 0 && e.OnWarn != nil {
+		timers = append(timers, p.clock.AfterFunc(e.Warn, func() { e.OnWarn(label, p.since(start)) }))
+	}
+	if e.Cancel > 0 {
+		timers = append(timers, p.clock.AfterFunc(e.Cancel, func() { cancel(ErrEscalated) }))
+	}
+	if e.Cancel <= 0 || e.Grace <= 0 {
+		return escalated(ctx, p.profiled(ctx, t, start))
+	}
+
+	outcome := make(chan error, 1) // not to block the function once abandoned
+	slot := &outputSlot{}          // the value produced by the attempt, dropped once abandoned
+	attemptCtx := context.WithValue(ctx, outputKey{}, slot)
+	if !p.tryGo(func() { outcome <- p.profiled(attemptCtx, t, start) }) {
+		return escalated(ctx, p.profiled(ctx, t, start)) // see WithMaxGoroutines
+	}
+	abandoned := make(chan struct{})
+	timers = append(timers, p.clock.AfterFunc(e.Cancel+e.Grace, func() { close(abandoned) }))
+	select {
+	case err := <-outcome:
+		t.output = slot.take()
+		return escalated(ctx, err)
+	case <-abandoned:
+		slot.take()
+		t.output = nil    // not to deliver the value of a previous attempt either
+		t.recycle = false // the function may still use t
+		w.abandoned = true
+		if e.OnAbandon != nil {
+			e.OnAbandon(label)
+		}
+		return ErrAbandoned
+	}
+}
+
+// escalated returns err, wrapping ErrEscalated if ctx was cancelled by the escalation of its function
+func escalated(ctx context.Context, err error) error {
+	if err == nil || context.Cause(ctx) != ErrEscalated || errors.Is(err, ErrEscalated) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrEscalated, err)
+}
+
+// outputKey is the key of the outputSlot of an attempt running in a goroutine of its own, see supervised
+type outputKey struct{}
+
+// outputSlot holds the value produced by an attempt running in a goroutine of its own, until the worker takes it.
+// Once taken, the values set by the attempt are dropped, e.g. when it's abandoned.
+type outputSlot struct {
+	mu     sync.Mutex
+	output func()
+	taken  bool
+}
+
+// take returns the value set by the attempt, dropping the ones set afterwards
+func (s *outputSlot) take() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taken = true
+	return s.output
+}
+
+// setOutput sets how to deliver the value produced by the attempt of t that received ctx: in the outputSlot of the
+// attempt if it has one, or in t
+func setOutput(ctx context.Context, t *task, output func()) {
+	s, _ := ctx.Value(outputKey{}).(*outputSlot)
+	if s == nil {
+		t.output = output
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.taken {
+		s.output = output
+	}
+}
@@ -0,0 +1,128 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEscalationCancel(t *testing.T) {
+	var warned atomic.Bool
+	p := New(1, WithEscalation(Escalation{
+		Warn:   time.Millisecond,
+		OnWarn: func(string, time.Duration) { warned.Store(true) },
+		Cancel: 5 * time.Millisecond,
+	}))
+	p.RunCtx(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return context.Cause(ctx)
+	})
+	if err := p.WaitErr(); !errors.Is(err, ErrEscalated) {
+		t.Errorf("got %v, want the function cancelled with ErrEscalated", err)
+	}
+	if !warned.Load() {
+		t.Error("no warning before the cancellation")
+	}
+}
+
+func TestEscalationAbandon(t *testing.T) {
+	release := make(chan struct{})
+	var abandoned atomic.Value
+	p := New(1, WithEscalation(Escalation{
+		Cancel:    time.Millisecond,
+		Grace:     5 * time.Millisecond,
+		OnAbandon: func(label string) { abandoned.Store(label) },
+	}))
+	p.RunNamed("stuck", func() { <-release }) // ignoring its cancellation
+	var ran atomic.Bool
+	p.Run(func() { ran.Store(true) })
+	err := p.WaitErr()
+	close(release)
+	if !errors.Is(err, ErrAbandoned) {
+		t.Errorf("got %v, want the stuck function abandoned", err)
+	}
+	if abandoned.Load() != "stuck" || !ran.Load() {
+		t.Errorf("abandoned %v, the next function ran: %v", abandoned.Load(), ran.Load())
+	}
+}
+
+// abandoning returns an escalation abandoning the functions quickly, closing release once it did
+func abandoning(release chan struct{}) Option {
+	return WithEscalation(Escalation{
+		Cancel:    5 * time.Millisecond,
+		Grace:     5 * time.Millisecond,
+		OnAbandon: func(string) { close(release) },
+	})
+}
+
+func TestEscalationAbandonedResult(t *testing.T) {
+	release, returned := make(chan struct{}), make(chan struct{})
+	p := NewOf[int](1, abandoning(release))
+	p.RunResult(func() (int, error) {
+		defer close(returned)
+		<-release // ignoring its cancellation, then returning while its worker delivers
+		return 1, nil
+	})
+	p.RunResult(func() (int, error) { return 2, nil })
+	var results []int
+	var errs []error
+	done, drained := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for v := range p.Results {
+			results = append(results, v)
+		}
+	}()
+	go func() {
+		defer close(drained)
+		for err := range p.Errors {
+			errs = append(errs, err)
+		}
+	}()
+	p.Wait()
+	<-done
+	<-drained
+	<-returned
+	time.Sleep(10 * time.Millisecond) // letting the abandoned function hand its value over
+	if len(results) != 1 || results[0] != 2 {
+		t.Errorf("got results %v, want [2]", results)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrAbandoned) {
+		t.Errorf("got errors %v, want ErrAbandoned", errs)
+	}
+}
+
+type testStatus bool
+
+func (s testStatus) Fail() bool { return !bool(s) }
+
+func TestEscalationAbandonedStatus(t *testing.T) {
+	release, returned := make(chan struct{}), make(chan struct{})
+	p := NewStatusPool[testStatus](1, abandoning(release))
+	p.RunStatus(func() testStatus {
+		defer close(returned)
+		<-release
+		return true
+	})
+	var statuses []testStatus
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for s := range p.Statuses {
+			statuses = append(statuses, s)
+		}
+	}()
+	go func() {
+		for range p.Errors {
+		}
+	}()
+	p.Wait()
+	<-done
+	<-returned
+	time.Sleep(10 * time.Millisecond)
+	if len(statuses) != 0 {
+		t.Errorf("got statuses %v from an abandoned function", statuses)
+	}
+}
@@ -0,0 +1,90 @@
+package cc
+
+import "time"
+
+// EventKind is the kind of an Event
+type EventKind int
+
+const (
+	EventSubmitted EventKind = iota // the function was queued
+	EventStarted                    // a worker started running the function
+	EventFinished                   // the function ended without error
+	EventFailed                     // the function ended with an error
+	EventRetried                    // the function failed and is going to be retried
+	EventSkipped                    // the function won't run because its context is done
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventSubmitted:
+		return "submitted"
+	case EventStarted:
+		return "started"
+	case EventFinished:
+		return "finished"
+	case EventFailed:
+		return "failed"
+	case EventRetried:
+		return "retried"
+	case EventSkipped:
+		return "skipped"
+	}
+	return "unknown"
+}
+
+// Event is a step in the life of a function run by a pool, see WithProgress
+type Event struct {
+	Kind    EventKind
+	Pool    string // the name of the pool
+	Label   string // the label of the function
+	Time    time.Time
+	Attempt int   // the number of the attempt, starting from 1
+	Err     error // the error of the function for EventFailed and EventRetried
+
+	// for EventFinished, EventFailed and EventRetried
+	Duration time.Duration // how long the attempt ran
+	Allocs   uint64        // the bytes allocated during the attempt, see WithAllocStats
+}
+
+// WithProgress makes the pool call fn at each step in the life of its functions, e.g. to render a progress bar.
+// fn is called from the goroutines submitting and running the functions, so it must be safe for concurrent use,
+// and fast.
+func WithProgress(fn func(Event)) Option {
+	return func(p *Pool) {
+		p.onEvent = fn
+	}
+}
+
+// emit calls the progress callback, if any, for the current attempt of t. It must not be called with p.mu held.
+func (p *Pool) emit(kind EventKind, t *task, at time.Time, err error) {
+	if p.onEvent == nil {
+		return
+	}
+	p.onEvent(Event{Kind: kind, Pool: p.name, Label: t.label, Time: at, Attempt: t.attempt + 1, Err: err})
+}
+
+// emitSubmitted calls the progress callback, if any, for a function just submitted. It takes the fields of the task
+// rather than the task, which may be running already.
+func (p *Pool) emitSubmitted(label string, at time.Time) {
+	if p.onEvent == nil {
+		return
+	}
+	p.onEvent(Event{Kind: EventSubmitted, Pool: p.name, Label: label, Time: at, Attempt: 1})
+}
+
+// emitEnd calls the progress callback, if any, for the run described by info
+func (p *Pool) emitEnd(kind EventKind, info TaskInfo) {
+	if p.onEvent == nil {
+		return
+	}
+	p.onEvent(Event{
+		Kind:     kind,
+		Pool:     info.Pool,
+		Label:    info.Label,
+		Time:     info.Start.Add(info.Duration),
+		Attempt:  info.Attempt,
+		Err:      info.Err,
+		Duration: info.Duration,
+		Allocs:   info.Allocs,
+	})
+}
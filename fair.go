@@ -0,0 +1,19 @@
+package cc
+
+// WithFairness makes the submitters of the pool take turns: the workers pick up the next function of each submitter
+// in round-robin, rather than in submission order, so that a chatty submitter can't starve the others sharing the
+// pool. A submitter coming back after being idle gets its turn in the current round, not the ones it missed.
+// Submitters are told apart with RunAs, and all the functions submitted otherwise share the same turn.
+// Priorities still come first, see RunPriority.
+func WithFairness() Option {
+	return func(p *Pool) {
+		p.queue.fair = true
+	}
+}
+
+// RunAs is like Run, but fn is submitted on behalf of submitter, see WithFairness
+func (p *Pool) RunAs(submitter string, fn func()) error {
+	t := plainTask(fn)
+	t.submitter = submitter
+	return p.push(t)
+}
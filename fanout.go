@@ -0,0 +1,89 @@
+package cc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FanoutError is the error of a WriterFanout once destinations failed, see WriterFanout.Err
+type FanoutError struct {
+	Errs []error // the error of each destination, in the order they were given, nil for the ones still written to
+}
+
+func (e *FanoutError) Error() string {
+	var msgs []string
+	for i, err := range e.Errs {
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("destination %d: %s", i, err))
+		}
+	}
+	return fmt.Sprintf("cc: %d of %d destinations failed: %s", len(msgs), len(e.Errs), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the destinations that failed, for errors.Is and errors.As
+func (e *FanoutError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// WriterFanout is the writer returned by NewWriterFanout
+type WriterFanout struct {
+	p       *Pool
+	writers []io.Writer
+	errs    []error
+	failed  int
+}
+
+// NewWriterFanout returns a writer duplicating what it's written to writers, each call to Write writing to them
+// concurrently as functions of p, within its limits, and returning once they all returned, e.g. to flash the same
+// firmware or disk image to several targets at once. Unlike io.MultiWriter a destination failing doesn't stop the
+// others: it's left out of the next writes, which succeed as long as one destination takes the data, so that io.Copy
+// goes on for the healthy ones. Once the writing is over, Err reports the destinations that failed. The writes fail
+// with a *FanoutError only once all the destinations failed. The writes must not be concurrent.
+func NewWriterFanout(p *Pool, writers ...io.Writer) *WriterFanout {
+	return &WriterFanout{p: p, writers: writers, errs: make([]error, len(writers))}
+}
+
+func (f *WriterFanout) Write(b []byte) (int, error) {
+	tasks := make([]*Task[struct{}], len(f.writers))
+	for i, w := range f.writers {
+		if f.errs[i] == nil {
+			tasks[i] = Submit(f.p, func(context.Context) (struct{}, error) {
+				n, err := w.Write(b)
+				if err == nil && n < len(b) {
+					err = io.ErrShortWrite
+				}
+				return struct{}{}, err
+			})
+		}
+	}
+	for i, t := range tasks {
+		if t == nil {
+			continue
+		}
+		if _, err := t.Result(); err != nil {
+			f.errs[i] = err
+			f.failed++
+		}
+	}
+	if f.failed == len(f.writers) && len(f.writers) > 0 {
+		return 0, f.Err()
+	}
+	return len(b), nil
+}
+
+// Err returns a *FanoutError with the error of every destination that failed so far, or nil if none did.
+// It must not be called concurrently with Write.
+func (f *WriterFanout) Err() error {
+	if f.failed == 0 {
+		return nil
+	}
+	return &FanoutError{Errs: append([]error(nil), f.errs...)}
+}
@@ -0,0 +1,53 @@
+package cc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails every write after the first n bytes
+type failingWriter struct {
+	n   int
+	err error
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if len(b) > w.n {
+		return 0, w.err
+	}
+	w.n -= len(b)
+	return len(b), nil
+}
+
+func TestWriterFanoutAllFailing(t *testing.T) {
+	p := New(2)
+	defer p.Wait()
+	boom := errors.New("boom")
+	f := NewWriterFanout(p, &failingWriter{err: boom}, &failingWriter{err: boom})
+	if n, err := f.Write([]byte("data")); n != 0 || !errors.Is(err, boom) {
+		t.Errorf("got %d, %v, want 0 and the errors of the destinations", n, err)
+	}
+}
+
+func TestWriterFanoutFailingDestination(t *testing.T) {
+	p := New(2)
+	defer p.Wait()
+	var a, b bytes.Buffer
+	boom := errors.New("boom")
+	f := NewWriterFanout(p, &a, &failingWriter{n: 4, err: boom}, &b)
+	data := strings.Repeat("firmware", 1000)
+	n, err := io.CopyBuffer(f, struct{ io.Reader }{strings.NewReader(data)}, make([]byte, 16)) // in several writes
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("copied %d bytes with %v, want %d", n, err, len(data))
+	}
+	if a.String() != data || b.String() != data {
+		t.Error("the healthy destinations didn't get all the data")
+	}
+	var fe *FanoutError
+	if err := f.Err(); !errors.As(err, &fe) || !errors.Is(err, boom) || fe.Errs[0] != nil || fe.Errs[2] != nil {
+		t.Errorf("got %v, want the error of the second destination only", err)
+	}
+}
@@ -0,0 +1,75 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// errBodyConsumed is the error of a request that can't be retried, as its body was consumed by the previous attempt
+var errBodyConsumed = errors.New("cc: can't retry a request whose body can't be rewound")
+
+// FetchAll sends reqs with client, with at most concurrency requests at the same time, and returns the responses and
+// the errors aligned with reqs. The bodies of the responses must be closed by the caller. The timeout of client
+// applies to each request, and the options configure the pool sending them: with WithRetry the requests failing, or
+// answered with a 5xx status, are sent again if their body can be rewound, see http.Request.GetBody.
+// A request answered with a 5xx status on its last attempt gets an error and no response, like the requests the pool
+// refuses.
+func FetchAll(ctx context.Context, client *http.Client, reqs []*http.Request, concurrency int, opts ...Option) ([]*http.Response, []error) {
+	resps := make([]*http.Response, len(reqs))
+	errs := make([]error, len(reqs))
+	var wg sync.WaitGroup
+	tasks := make([]*task, len(reqs))
+	for i, req := range reqs {
+		sent := false
+		tasks[i] = &task{
+			label: req.Method + " " + req.URL.String(),
+			fn: func(ctx context.Context) error {
+				if sent && req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+					return errBodyConsumed
+				}
+				sent = true
+				resp, err := fetch(ctx, client, req)
+				resps[i] = resp
+				return err
+			},
+			onDone: func(err error) {
+				errs[i] = err
+				wg.Done()
+			},
+		}
+	}
+	p := NewWithContext(ctx, concurrency, opts...)
+	wg.Add(len(tasks))
+	handled, err := p.pushAll(tasks)
+	for i := handled; i < len(tasks); i++ {
+		errs[i] = err // refused by the pool, so their onDone won't run
+		wg.Done()
+	}
+	wg.Wait()
+	p.Wait()
+	return resps, errs
+}
+
+// fetch sends a copy of req bound to ctx, rewinding its body for the retries
+func fetch(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
+	req = req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 500 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cc: %s", resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,82 @@
+package cc
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrCanceled is the error of a Task canceled before it started running
+var ErrCanceled = fmt.Errorf("cc: task canceled: %w", context.Canceled)
+
+// Task is a handle on a function submitted with Submit, to wait for its result or cancel it
+type Task[T any] struct {
+	pool   *Pool
+	task   *task
+	done   chan struct{}
+	value  T
+	err    error
+	cancel context.CancelCauseFunc
+}
+
+// Submit queues fn in p like RunCtx does, and returns a handle on it. The outcome of fn is delivered to the handle
+// rather than to Errors: if fn fails, or never runs because its context is done, the handle reports the error.
+// If p is closed the returned handle is done already, with ErrPoolClosed.
+func Submit[T any](p *Pool, fn func(ctx context.Context) (T, error)) *Task[T] {
+	t := newTask(p, fn)
+	if err := p.push(t.task); err != nil {
+		t.task.onDone(err)
+	}
+	return t
+}
+
+// newTask returns a handle on fn, not queued yet
+func newTask[T any](p *Pool, fn func(ctx context.Context) (T, error)) *Task[T] {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	t := &Task[T]{pool: p, done: make(chan struct{}), cancel: cancel}
+	t.task = &task{
+		ctx: ctx,
+		produce: func(ctx context.Context) (func(), error) {
+			v, err := fn(ctx)
+			return func() { t.value = v }, err
+		},
+		onDone: t.finish,
+	}
+	return t
+}
+
+func (t *Task[T]) finish(err error) {
+	t.err = err
+	t.cancel(nil)
+	close(t.done)
+}
+
+// Done returns a channel closed when the task is done
+func (t *Task[T]) Done() <-chan struct{} {
+	return t.done
+}
+
+// Err returns the error of the task once it's done, or nil while it's still queued or running
+func (t *Task[T]) Err() error {
+	select {
+	case <-t.done:
+		return t.err
+	default:
+		return nil
+	}
+}
+
+// Result blocks until the task is done and returns the value and the error of its function
+func (t *Task[T]) Result() (T, error) {
+	<-t.done
+	return t.value, t.err
+}
+
+// Cancel cancels the task. If it's still queued it's removed from the queue right away, freeing its spot,
+// and its error is ErrCanceled. If it's running its context is cancelled, and the outcome is whatever its function
+// returns then.
+func (t *Task[T]) Cancel() {
+	t.cancel(ErrCanceled)
+	if t.pool.unqueue(t.task) {
+		t.task.onDone(ErrCanceled) // not just finish, e.g. for RunOnce to free the key
+	}
+}
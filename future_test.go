@@ -0,0 +1,68 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSubmit(t *testing.T) {
+	p := New(2)
+	errs := drain(p)
+	failure := errors.New("failure")
+	ok := Submit(p, func(context.Context) (int, error) { return 42, nil })
+	ko := Submit(p, func(context.Context) (int, error) { return 0, failure })
+	if v, err := ok.Result(); v != 42 || err != nil {
+		t.Errorf("got %v, %v, want 42", v, err)
+	}
+	if _, err := ko.Result(); !errors.Is(err, failure) {
+		t.Errorf("got error %v, want the failure", err)
+	}
+	p.Wait()
+	if got := errs(); len(got) != 0 {
+		t.Errorf("got errors %v, want them delivered to the handles only", got)
+	}
+	if err := Submit(p, func(context.Context) (int, error) { return 0, nil }).Err(); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("got %v submitting to a closed pool, want ErrPoolClosed", err)
+	}
+}
+
+func TestSubmitCancelRunning(t *testing.T) {
+	p := New(1)
+	errs := drain(p)
+	started := make(chan struct{})
+	task := Submit(p, func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	<-started
+	task.Cancel()
+	if _, err := task.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want the cancellation", err)
+	}
+	p.Wait()
+	errs()
+}
+
+func TestSubmitCancelQueued(t *testing.T) {
+	p := NewBounded(1, 1)
+	errs := drain(p)
+	release := make(chan struct{})
+	p.Run(func() { <-release })
+	ran := false
+	task := Submit(p, func(context.Context) (int, error) { ran = true; return 0, nil })
+	task.Cancel()
+	if err := task.Err(); !errors.Is(err, ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v canceling a queued task, want ErrCanceled right away", err)
+	}
+	if err := p.Run(func() {}); err != nil { // taking the spot freed in the queue
+		t.Fatal(err)
+	}
+	close(release)
+	p.Wait()
+	errs()
+	if ran {
+		t.Error("the canceled task ran")
+	}
+}
@@ -0,0 +1,67 @@
+package cc
+
+import "fmt"
+
+// WithMaxGoroutines caps at n the goroutines of the pool: its workers plus the helpers it starts, e.g. for timers, hedged
+// attempts or control loops, so that a pool in a small container or a CI runner stays within known bounds. The workers
+// and the optional helpers are not started beyond the cap: the pool then runs fewer functions at the same time, hedged
+// functions don't launch their second attempt, and escalated functions run in their worker, so they can't be abandoned.
+// The helpers the pool can't do without, at most one per feature enabled plus the one of Wait, are always started: they
+// count, leaving less room for the workers, but may take the pool over the cap by that many.
+func WithMaxGoroutines(n int) Option {
+	return func(p *Pool) {
+		p.maxGoroutines = n
+	}
+}
+
+// GoroutineCount returns the number of goroutines of the pool running right now, its workers and its helpers, see
+// WithMaxGoroutines. It's 0 once the pool is over and all of them exited.
+func (p *Pool) GoroutineCount() int {
+	return int(p.goroutines.Load())
+}
+
+// goTracked runs fn in a new goroutine, counted by the pool and by VerifyNoLeaks until it returns
+func (p *Pool) goTracked(fn func()) {
+	p.goroutines.Add(1)
+	p.goTrackedSlot(fn)
+}
+
+// tryGo is like goTracked, unless the pool has no room for another goroutine: it returns false then
+func (p *Pool) tryGo(fn func()) bool {
+	for {
+		n := p.goroutines.Load()
+		if p.maxGoroutines > 0 && n >= int64(p.maxGoroutines) {
+			return false
+		}
+		if p.goroutines.CompareAndSwap(n, n+1) {
+			p.goTrackedSlot(fn)
+			return true
+		}
+	}
+}
+
+// goTrackedSlot runs fn in a new goroutine already counted by the pool, failing loudly if the count goes wrong
+func (p *Pool) goTrackedSlot(fn func()) {
+	goTracked(func() {
+		defer func() {
+			if n := p.goroutines.Add(-1); n < 0 {
+				panic(fmt.Sprintf("cc: goroutine accounting of the pool went negative: %d", n))
+			}
+			if p.maxGoroutines > 0 {
+				// a worker may have been kept from starting for lack of room
+				p.mu.Lock()
+				p.wakeWorker()
+				p.mu.Unlock()
+			}
+		}()
+		fn()
+	})
+}
+
+// checkWorkersGone fails loudly if workers are still accounted for once they all exited. It must be called with p.mu
+// held.
+func (p *Pool) checkWorkersGone() {
+	if !p.serial && p.nworkers != 0 {
+		panic(fmt.Sprintf("cc: %d workers of the pool still accounted for after they all exited", p.nworkers))
+	}
+}
@@ -0,0 +1,172 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Graph is a set of named functions depending on each other, run in a pool by Run
+type Graph struct {
+	nodes map[string]*Node
+	order []*Node // in declaration order
+}
+
+// Node is a function of a Graph, see Graph.Task
+type Node struct {
+	name  string
+	fn    func(ctx context.Context) error
+	after []string
+
+	// the state of a run
+	waiting    int     // the number of dependencies not done yet
+	dependents []*Node // the nodes depending on this one
+	err        error   // the error of the first dependency that failed
+}
+
+// DependencyError is the error of a function of a Graph that didn't run because one of its dependencies failed
+type DependencyError struct {
+	Task       string
+	Dependency string
+	Err        error // the error of the dependency
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("cc: %s: dependency %s failed: %s", e.Task, e.Dependency, e.Err)
+}
+
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
+
+// NewGraph returns an empty graph
+func NewGraph() *Graph {
+	return &Graph{nodes: map[string]*Node{}}
+}
+
+// Task adds the function fn named name to the graph, replacing any function with the same name. Its dependencies
+// are declared with After.
+func (g *Graph) Task(name string, fn func(ctx context.Context) error) *Node {
+	n := &Node{name: name, fn: fn}
+	if old := g.nodes[name]; old != nil {
+		*old = *n
+		return old
+	}
+	g.nodes[name] = n
+	g.order = append(g.order, n)
+	return n
+}
+
+// After makes the function of n wait for the functions with the given names to succeed. If one of them fails
+// the function of n doesn't run, and fails with a DependencyError.
+func (n *Node) After(names ...string) *Node {
+	n.after = append(n.after, names...)
+	return n
+}
+
+// Run runs the functions of the graph in p, each one as soon as its dependencies succeeded, and blocks until they
+// all end. It returns their errors joined with errors.Join instead of sending them to Errors, or an error before
+// running anything if a dependency is unknown or the dependencies form a cycle.
+func (g *Graph) Run(p *Pool) error {
+	if err := g.prepare(); err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	var start func(n *Node)
+	finish := func(n *Node, err error) {
+		var ready []*Node
+		mu.Lock()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, d := range n.dependents {
+			if err != nil && d.err == nil {
+				d.err = &DependencyError{Task: d.name, Dependency: n.name, Err: err}
+			}
+			d.waiting--
+			if d.waiting == 0 {
+				ready = append(ready, d)
+			}
+		}
+		mu.Unlock()
+		for _, d := range ready {
+			// not from the worker calling finish, that would block on the queue of a bounded pool
+			p.goTracked(func() { start(d) })
+		}
+		wg.Done()
+	}
+	start = func(n *Node) {
+		mu.Lock()
+		err := n.err
+		mu.Unlock()
+		if err != nil {
+			finish(n, err)
+			return
+		}
+		t := &task{label: n.name, fn: n.fn, onDone: func(err error) { finish(n, err) }}
+		if err := p.push(t); err != nil {
+			finish(n, err)
+		}
+	}
+
+	wg.Add(len(g.order))
+	var roots []*Node
+	for _, n := range g.order {
+		if n.waiting == 0 {
+			roots = append(roots, n)
+		}
+	}
+	for _, n := range roots {
+		start(n)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// prepare resets the state of the nodes for a run, checking that the dependencies exist and have no cycle
+func (g *Graph) prepare() error {
+	for _, n := range g.order {
+		n.waiting, n.dependents, n.err = 0, nil, nil
+	}
+	for _, n := range g.order {
+		for _, name := range n.after {
+			dep := g.nodes[name]
+			if dep == nil {
+				return fmt.Errorf("cc: %s: unknown dependency %s", n.name, name)
+			}
+			dep.dependents = append(dep.dependents, n)
+			n.waiting++
+		}
+	}
+
+	// Kahn's algorithm: the nodes never freed are part of a cycle, or depend on one
+	waiting := map[*Node]int{}
+	var free []*Node
+	for _, n := range g.order {
+		waiting[n] = n.waiting
+		if n.waiting == 0 {
+			free = append(free, n)
+		}
+	}
+	for len(free) > 0 {
+		n := free[len(free)-1]
+		free = free[:len(free)-1]
+		delete(waiting, n)
+		for _, d := range n.dependents {
+			waiting[d]--
+			if waiting[d] == 0 {
+				free = append(free, d)
+			}
+		}
+	}
+	for _, n := range g.order {
+		if _, ok := waiting[n]; ok {
+			return fmt.Errorf("cc: %s: dependency cycle", n.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestGraphOrder(t *testing.T) {
+	p := New(4)
+	defer p.WaitErr()
+	var mu sync.Mutex
+	var order []string
+	step := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+	g := NewGraph()
+	g.Task("link", step("link")).After("compile", "assets")
+	g.Task("compile", step("compile")).After("fetch")
+	g.Task("assets", step("assets")).After("fetch")
+	g.Task("fetch", step("fetch"))
+	if err := g.Run(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 4 || order[0] != "fetch" || order[3] != "link" {
+		t.Errorf("ran %v, want fetch first and link last", order)
+	}
+}
+
+func TestGraphDependencyFailed(t *testing.T) {
+	p := New(2)
+	defer p.WaitErr()
+	failure := errors.New("failure")
+	ran := false
+	g := NewGraph()
+	g.Task("a", func(context.Context) error { return failure })
+	g.Task("b", func(context.Context) error { ran = true; return nil }).After("a")
+	err := g.Run(p)
+	var de *DependencyError
+	if !errors.As(err, &de) || de.Task != "b" || de.Dependency != "a" || !errors.Is(err, failure) {
+		t.Errorf("got %v, want b failing because of a", err)
+	}
+	if ran {
+		t.Error("b ran after its dependency failed")
+	}
+}
+
+func TestGraphInvalid(t *testing.T) {
+	p := New(1)
+	defer p.WaitErr()
+	ran := false
+	noop := func(context.Context) error { ran = true; return nil }
+	for _, deps := range [][2][]string{{{"b"}, {"a"}}, {{"c"}, nil}} {
+		g := NewGraph()
+		g.Task("a", noop).After(deps[0]...)
+		g.Task("b", noop).After(deps[1]...)
+		if err := g.Run(p); err == nil {
+			t.Errorf("ran a graph with the dependencies %v", deps)
+		}
+	}
+	if ran {
+		t.Error("ran a function of an invalid graph")
+	}
+}
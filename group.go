@@ -0,0 +1,65 @@
+package cc
+
+import (
+	"context"
+	"sync"
+)
+
+// Group coordinates several pools, listed in dependency order: the pools feeding other pools come first.
+// It merges their errors in Errors, which must be consumed, and closes them one after the other.
+type Group struct {
+	Errors chan error
+
+	pools []*Pool
+	once  sync.Once
+}
+
+// NewGroup returns a group of pools, listed in dependency order. Their Errors are consumed by the group from now on.
+func NewGroup(pools ...*Pool) *Group {
+	g := &Group{Errors: make(chan error), pools: pools}
+	var wg sync.WaitGroup
+	for _, p := range pools {
+		wg.Add(1)
+		goTracked(func() {
+			defer wg.Done()
+			for err := range p.Errors {
+				if err != ErrDone {
+					g.Errors <- err
+				}
+			}
+		})
+	}
+	goTracked(func() {
+		wg.Wait()
+		close(g.Errors)
+	})
+	return g
+}
+
+// Wait doesn't block, but closes the pools in order, each one after the functions of the previous ones ended,
+// so that they can still feed the next pools. Errors is closed once all the pools are done.
+func (g *Group) Wait() {
+	g.once.Do(func() {
+		goTracked(func() {
+			for _, p := range g.pools {
+				p.Wait()
+				<-p.finished
+			}
+		})
+	})
+}
+
+// Shutdown is like Wait but blocks, stopping the pools in order with Stop. If ctx is done first the pools not done yet
+// are all cancelled, and Shutdown returns the error of ctx. Errors must still be consumed while Shutdown is waiting.
+func (g *Group) Shutdown(ctx context.Context) error {
+	for i, p := range g.pools {
+		if err := p.Stop(ctx); err != nil {
+			for _, next := range g.pools[i+1:] {
+				next.cancelWith(ErrStopped, context.Cause(ctx))
+				next.Wait()
+			}
+			return err
+		}
+	}
+	return nil
+}
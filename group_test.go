@@ -0,0 +1,28 @@
+package cc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	defer VerifyNoLeaks(t)
+	failure := errors.New("failure")
+	first, second := New(1), New(1)
+	g := NewGroup(first, second)
+	first.Go(func() error {
+		second.Go(func() error { return failure }) // still open since first isn't done
+		return failure
+	})
+	g.Wait()
+	var n int
+	for err := range g.Errors {
+		if !errors.Is(err, failure) {
+			t.Errorf("got %v, want the failures of the pools", err)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("got %d errors, want 2", n)
+	}
+}
@@ -0,0 +1,98 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoHeartbeat is the cause of the cancellation of a function that stopped sending heartbeats, see WithHeartbeat
+var ErrNoHeartbeat = errors.New("cc: no heartbeat")
+
+// TaskContext is the context of a function run with RunHeartbeat, which calls Heartbeat to tell it's still making
+// progress
+type TaskContext struct {
+	context.Context
+	last   atomic.Int64 // the time of the last heartbeat, in Unix nanoseconds
+	cancel context.CancelCauseFunc
+	clock  Clock
+}
+
+// Heartbeat records that the function is still making progress
+func (c *TaskContext) Heartbeat() {
+	c.last.Store(c.clock.Now().UnixNano())
+}
+
+type heartbeat struct {
+	threshold time.Duration
+	cancel    bool
+}
+
+// WithHeartbeat makes the pool consider the functions run with RunHeartbeat stale when they don't call Heartbeat for
+// more than threshold, see Stale. With cancel the stale functions have their context cancelled, with ErrNoHeartbeat
+// as the cause. It helps detecting wedged functions when a timeout is hard to pick up front.
+func WithHeartbeat(threshold time.Duration, cancel bool) Option {
+	return func(p *Pool) {
+		p.heartbeat = &heartbeat{threshold: threshold, cancel: cancel}
+	}
+}
+
+// RunHeartbeat is like RunCtx, but fn receives a TaskContext to send heartbeats with. Each attempt of the function gets
+// a TaskContext of its own, whose first heartbeat is recorded when the attempt starts, before the middlewares run.
+func (p *Pool) RunHeartbeat(fn func(ctx *TaskContext) error) error {
+	t := &task{beat: &TaskContext{clock: p.clock}}
+	t.fn = func(ctx context.Context) error {
+		// a context of its own for each attempt, so that a stale attempt doesn't doom the retries
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		p.mu.Lock()
+		tc := t.beat // the TaskContext of this attempt, see started
+		tc.Context, tc.cancel = ctx, cancel
+		p.mu.Unlock()
+		return fn(tc)
+	}
+	return p.push(t)
+}
+
+// Stale returns the functions run with RunHeartbeat that didn't send a heartbeat within the threshold given to
+// WithHeartbeat, or nil without it
+func (p *Pool) Stale() []RunningTask {
+	var stale []RunningTask
+	p.staleTasks(func(t *task, start time.Time) {
+		stale = append(stale, RunningTask{Label: t.label, Start: start})
+	})
+	return stale
+}
+
+// staleTasks calls fn, with p.mu held, on the running tasks whose last heartbeat is too old
+func (p *Pool) staleTasks(fn func(t *task, start time.Time)) {
+	if p.heartbeat == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for t, start := range p.running {
+		if t.beat != nil && p.since(time.Unix(0, t.beat.last.Load())) > p.heartbeat.threshold {
+			fn(t, start)
+		}
+	}
+}
+
+// heartbeatLoop cancels the stale tasks until the pool is finished
+func (p *Pool) heartbeatLoop() {
+	ticks, stop := tick(p.clock, max(p.heartbeat.threshold/2, time.Millisecond))
+	defer stop()
+	for {
+		select {
+		case <-ticks:
+		case <-p.finished:
+			return
+		}
+		p.staleTasks(func(t *task, _ time.Time) {
+			if t.beat.cancel != nil { // nil until the function of the attempt starts, past the middlewares
+				t.beat.cancel(ErrNoHeartbeat)
+			}
+		})
+	}
+}
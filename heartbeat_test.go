@@ -0,0 +1,98 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatCancelsStale(t *testing.T) {
+	p := New(2, WithHeartbeat(10*time.Millisecond, true))
+	p.RunHeartbeat(func(ctx *TaskContext) error {
+		for range 10 {
+			time.Sleep(2 * time.Millisecond)
+			ctx.Heartbeat()
+		}
+		return context.Cause(ctx)
+	})
+	p.RunHeartbeat(func(ctx *TaskContext) error {
+		<-ctx.Done() // wedged, without heartbeats
+		return context.Cause(ctx)
+	})
+	err := p.WaitErr()
+	if !errors.Is(err, ErrNoHeartbeat) {
+		t.Fatalf("got %v, want the wedged function cancelled", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 1 {
+		t.Errorf("got %v, want the function sending heartbeats left alone", err)
+	}
+}
+
+func TestHeartbeatStale(t *testing.T) {
+	p := New(1, WithHeartbeat(5*time.Millisecond, false))
+	release := make(chan struct{})
+	p.RunHeartbeat(func(ctx *TaskContext) error {
+		<-release
+		return nil
+	})
+	time.Sleep(20 * time.Millisecond)
+	if stale := p.Stale(); len(stale) != 1 {
+		t.Errorf("got %v stale, want the function without heartbeats", stale)
+	}
+	close(release)
+	if err := p.WaitErr(); err != nil {
+		t.Error(err)
+	}
+}
+
+// slowStart is a middleware delaying the start of the functions
+type slowStart struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m slowStart) Before(ctx context.Context, _ TaskInfo) (context.Context, error) {
+	close(m.started)
+	<-m.release
+	return ctx, nil
+}
+
+func (m slowStart) After(_ context.Context, _ TaskInfo, err error) error {
+	return err
+}
+
+func TestHeartbeatBeforeFunction(t *testing.T) {
+	mw := slowStart{started: make(chan struct{}), release: make(chan struct{})}
+	p := New(1, WithHeartbeat(time.Hour, true), WithMiddleware(mw))
+	p.RunHeartbeat(func(ctx *TaskContext) error {
+		return context.Cause(ctx)
+	})
+	<-mw.started
+	if stale := p.Stale(); len(stale) != 0 {
+		t.Errorf("got %v stale right after starting", stale)
+	}
+	close(mw.release)
+	if err := p.WaitErr(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHeartbeatRetry(t *testing.T) {
+	p := New(1, WithHeartbeat(10*time.Millisecond, true), WithRetry(2, nil))
+	var contexts []*TaskContext
+	p.RunHeartbeat(func(ctx *TaskContext) error {
+		contexts = append(contexts, ctx)
+		if len(contexts) == 1 {
+			<-ctx.Done() // wedged, without heartbeats
+			return context.Cause(ctx)
+		}
+		return ctx.Err()
+	})
+	if err := p.WaitErr(); err != nil {
+		t.Fatalf("got %v, want the retry to get a context of its own", err)
+	}
+	if len(contexts) != 2 || contexts[0] == contexts[1] {
+		t.Errorf("got %d attempts, want 2 with their own TaskContext", len(contexts))
+	}
+}
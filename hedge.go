@@ -0,0 +1,61 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrHedgeLost is the cause of the cancellation of the attempt of a hedged function that didn't end first, see RunHedged
+var ErrHedgeLost = errors.New("cc: the other attempt ended first")
+
+// RunHedged is like RunCtx, but if fn hasn't returned delay after it started, a second attempt of fn is launched
+// alongside the first one. The first attempt to return wins: its outcome is the outcome of the function, and the context
+// of the other attempt is cancelled with ErrHedgeLost as its cause, its outcome being ignored. It cuts the tail latency
+// of the calls to flaky mirrors or servers. The second attempt takes a slot of its own, waiting for one if the pool is
+// busy, so fn must be safe to call twice at the same time. If it wins, it keeps its slot until the first attempt
+// returns as well, so the pool stays within its concurrency.
+func (p *Pool) RunHedged(delay time.Duration, fn func(ctx context.Context) error) error {
+	t := &task{}
+	t.fn = func(ctx context.Context) error {
+		return p.hedge(ctx, t, delay, fn)
+	}
+	return p.push(t)
+}
+
+// hedge runs the attempts of the hedged task t, and returns the outcome of the first one to return
+func (p *Pool) hedge(ctx context.Context, t *task, delay time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(ErrHedgeLost)
+	outcomes := make(chan error, 2)
+	first := make(chan struct{}) // closed once the first attempt returned
+	attempt := func() {
+		outcomes <- func() (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = p.recovered(t, r)
+				}
+			}()
+			return fn(ctx)
+		}()
+	}
+	if !p.tryGo(func() { defer close(first); attempt() }) {
+		attempt() // no room for a second attempt either, see WithMaxGoroutines
+		return <-outcomes
+	}
+	timer := p.clock.AfterFunc(delay, func() {
+		p.tryGo(func() {
+			release, err := p.acquire(ctx, 1)
+			if err != nil {
+				return // the first attempt ended meanwhile
+			}
+			defer release()
+			if ctx.Err() == nil {
+				attempt()
+				<-first // the slot of t is gone once this attempt won, the first one running in this slot instead
+			}
+		})
+	})
+	defer timer.Stop()
+	return <-outcomes
+}
@@ -0,0 +1,71 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunHedged(t *testing.T) {
+	p := New(2)
+	var attempts atomic.Int32
+	lost := make(chan error, 1)
+	start := time.Now()
+	p.RunHedged(5*time.Millisecond, func(ctx context.Context) error {
+		if attempts.Add(1) == 1 {
+			<-ctx.Done() // the first attempt is stuck until the second one wins
+			lost <- context.Cause(ctx)
+			return errors.New("lost")
+		}
+		return nil
+	})
+	if err := p.WaitErr(); err != nil {
+		t.Fatalf("got %v, want the outcome of the second attempt", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("the hedged function took %s", elapsed)
+	}
+	if cause := <-lost; !errors.Is(cause, ErrHedgeLost) {
+		t.Errorf("the first attempt was cancelled with %v, want ErrHedgeLost", cause)
+	}
+}
+
+func TestRunHedgedFast(t *testing.T) {
+	p := New(2)
+	var attempts atomic.Int32
+	p.RunHedged(time.Hour, func(context.Context) error { attempts.Add(1); return nil })
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("made %d attempts of a function returning before the delay, want 1", n)
+	}
+}
+
+func TestRunHedgedConcurrency(t *testing.T) {
+	p := New(2)
+	var pk peak
+	var attempts atomic.Int32
+	won := make(chan struct{})
+	p.RunHedged(5*time.Millisecond, func(ctx context.Context) error {
+		if attempts.Add(1) == 1 {
+			<-ctx.Done()
+			pk.run(30 * time.Millisecond) // taking its time to return once the second attempt won
+			return ctx.Err()
+		}
+		close(won)
+		return nil
+	})
+	<-won
+	for range 4 {
+		p.Run(func() { pk.run(10 * time.Millisecond) })
+	}
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	if pk.high > 2 {
+		t.Errorf("%d functions ran at the same time in a pool of 2 after the second attempt won", pk.high)
+	}
+}
@@ -0,0 +1,140 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// Map calls fn on each one of items, with at most concurrency calls at the same time, and returns the results in the
+// same order as items. The errors returned by fn are joined with errors.Join.
+func Map[T, R any](items []T, concurrency int, fn func(T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	p := New(concurrency)
+	for i, item := range items {
+		p.Go(func() error {
+			r, err := fn(item)
+			results[i] = r
+			return err
+		})
+	}
+	return results, p.WaitErr()
+}
+
+// ForEach calls fn on each one of items, with at most concurrency calls at the same time. It stops calling fn on new
+// items as soon as a call returns an error or ctx is cancelled, and the context given to the running calls
+// is cancelled too. The errors are joined with errors.Join, along with the error of ctx if it was cancelled and the
+// reason the pool refused an item if it did.
+func ForEach[T any](ctx context.Context, items []T, concurrency int, fn func(ctx context.Context, i int, item T) error) error {
+	p := NewWithContext(ctx, concurrency, WithFailFast())
+	var refused error
+	for i, item := range items {
+		if p.ctx.Err() != nil {
+			break
+		}
+		if refused = p.push(&task{fn: func(ctx context.Context) error {
+			return fn(ctx, i, item)
+		}}); refused != nil {
+			break
+		}
+	}
+	err := p.WaitErr()
+	if refused != nil {
+		err = errors.Join(refused, err)
+	}
+	if ctx.Err() != nil {
+		return errors.Join(err, ctx.Err())
+	}
+	return err
+}
+
+// Chunks splits items in chunks of chunkSize items (the last one may be shorter) and calls fn on each chunk,
+// with at most concurrency calls at the same time. The errors returned by fn are joined with errors.Join.
+func Chunks[T any](items []T, chunkSize, concurrency int, fn func(chunk []T) error) error {
+	p := New(concurrency)
+	for chunk := range slices.Chunk(items, max(chunkSize, 1)) {
+		p.Go(func() error {
+			return fn(chunk)
+		})
+	}
+	return p.WaitErr()
+}
+
+// Collect calls all of fns, with at most concurrency calls at the same time, and returns their results and errors
+// aligned with fns: the result and the error of fns[i] are results[i] and errs[i], errs[i] being nil if it succeeded.
+// The functions the pool refuses get the reason as their error.
+func Collect[T any](concurrency int, fns ...func() (T, error)) (results []T, errs []error) {
+	results = make([]T, len(fns))
+	errs = make([]error, len(fns))
+	var wg sync.WaitGroup
+	tasks := make([]*task, len(fns))
+	for i, fn := range fns {
+		tasks[i] = &task{
+			fn: func(context.Context) error {
+				r, err := fn()
+				results[i] = r
+				return err
+			},
+			onDone: func(err error) {
+				errs[i] = err
+				wg.Done()
+			},
+		}
+	}
+	p := New(concurrency)
+	wg.Add(len(tasks))
+	handled, err := p.pushAll(tasks)
+	for i := handled; i < len(tasks); i++ {
+		errs[i] = err // refused by the pool, so their onDone won't run
+		wg.Done()
+	}
+	wg.Wait()
+	p.Wait()
+	return results, errs
+}
+
+// Reduce calls mapFn on each one of items, with at most concurrency calls at the same time, and folds the results
+// with reduceFn into a single value. The results are folded pairwise, in parallel too, as a tree in the order of items,
+// so reduceFn must be associative but needn't be commutative. The items whose mapFn fails are left out of the value,
+// and the errors are joined with errors.Join. Reduce returns the zero value of A if there is no result to fold.
+func Reduce[T, A any](items []T, concurrency int, mapFn func(T) (A, error), reduceFn func(A, A) A) (A, error) {
+	results := make([]A, len(items))
+	succeeded := make([]bool, len(items))
+	p := New(concurrency)
+	for i, item := range items {
+		p.Go(func() error {
+			r, err := mapFn(item)
+			results[i], succeeded[i] = r, err == nil
+			return err
+		})
+	}
+	errs := []error{p.WaitBatch()}
+
+	level := make([]A, 0, len(items))
+	for i, r := range results {
+		if succeeded[i] {
+			level = append(level, r)
+		}
+	}
+	for len(level) > 1 {
+		next := make([]A, (len(level)+1)/2)
+		for i := range len(level) / 2 {
+			p.Run(func() {
+				next[i] = reduceFn(level[2*i], level[2*i+1])
+			})
+		}
+		if len(level)%2 == 1 {
+			next[len(next)-1] = level[len(level)-1]
+		}
+		errs = append(errs, p.WaitBatch())
+		level = next
+	}
+	errs = append(errs, p.WaitErr())
+
+	var value A
+	if len(level) == 1 {
+		value = level[0]
+	}
+	return value, errors.Join(errs...)
+}
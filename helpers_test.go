@@ -0,0 +1,68 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	var pk peak
+	items := []int{5, 4, 3, 2, 1, 0}
+	failure := errors.New("zero")
+	results, err := Map(items, 2, func(i int) (string, error) {
+		pk.run(time.Duration(i) * time.Millisecond) // the first items end last
+		if i == 0 {
+			return "", failure
+		}
+		return strconv.Itoa(i), nil
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("got error %v, want the failure of the last item", err)
+	}
+	if want := []string{"5", "4", "3", "2", "1", ""}; !slices.Equal(results, want) {
+		t.Errorf("got results %q, want %q", results, want)
+	}
+	if pk.high != 2 {
+		t.Errorf("%d calls ran at the same time, want 2", pk.high)
+	}
+}
+
+func TestForEachEarlyExit(t *testing.T) {
+	failure := errors.New("failure")
+	var calls atomic.Int32
+	err := ForEach(context.Background(), make([]int, 100), 2, func(ctx context.Context, i int, _ int) error {
+		calls.Add(1)
+		if i == 3 {
+			return failure
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Millisecond):
+		}
+		return nil
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("got error %v, want the failure", err)
+	}
+	if n := calls.Load(); n == 100 {
+		t.Error("kept calling fn after a failure")
+	}
+}
+
+func TestForEachCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := ForEach(ctx, make([]int, 10), 1, func(ctx context.Context, i int, _ int) error {
+		if i == 0 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want the cancellation of the context", err)
+	}
+}
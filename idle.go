@@ -0,0 +1,27 @@
+package cc
+
+import "time"
+
+// WithIdleTimeout makes the workers of the pool exit after d without work. Workers are started again on demand when
+// new functions are submitted, so that long-lived pools seeing occasional bursts don't keep goroutines parked forever.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(p *Pool) {
+		p.idleTimeout = d
+	}
+}
+
+// wakeAll wakes all the idle workers up, so that they check their idle timeout
+func (p *Pool) wakeAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notEmpty.Broadcast()
+}
+
+// OnIdle registers fn to be called whenever the pool becomes idle, once the last pending function is done. It's called
+// from the goroutine that marked the function as done, usually a worker, and functions submitted meanwhile may be
+// running already. Long-lived pools use it to flush buffers between bursts.
+func (p *Pool) OnIdle(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onIdle = append(p.onIdle, fn)
+}
@@ -0,0 +1,23 @@
+package cc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdleTimeoutTimers(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	p := New(1, WithClock(clock), WithIdleTimeout(time.Hour))
+	for range 10 {
+		done := make(chan struct{})
+		p.Run(func() { close(done) })
+		<-done
+		time.Sleep(time.Millisecond) // for the worker to wait for work again
+	}
+	if n := clock.Timers(); n > 1 {
+		t.Errorf("%d idle timers pending, want the one of the idle worker at most", n)
+	}
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+}
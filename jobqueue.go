@@ -0,0 +1,113 @@
+package cc
+
+import (
+	"context"
+	"sync"
+)
+
+// Job is a unit of work stored in a Queue: the name of its handler and a serialized payload, so that it can be kept
+// outside the process, e.g. on disk or in Redis, and survive restarts
+type Job struct {
+	Name    string
+	Payload []byte
+}
+
+// Queue stores jobs waiting to be run by Pool.Serve. Pop blocks until a job is available, and returns the error
+// of ctx if it's done first. Implementations must be safe for concurrent use.
+type Queue interface {
+	Push(job Job) error
+	Pop(ctx context.Context) (Job, error)
+	Len() int
+}
+
+// MemoryQueue is the in-memory Queue, jobs are popped in the order they were pushed
+type MemoryQueue struct {
+	mu    sync.Mutex
+	jobs  []Job
+	ready chan struct{} // closed and replaced when a job is pushed
+}
+
+// NewMemoryQueue returns an empty MemoryQueue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{ready: make(chan struct{})}
+}
+
+func (q *MemoryQueue) Push(job Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs = append(q.jobs, job)
+	close(q.ready)
+	q.ready = make(chan struct{})
+	return nil
+}
+
+func (q *MemoryQueue) Pop(ctx context.Context) (Job, error) {
+	for {
+		q.mu.Lock()
+		if len(q.jobs) > 0 {
+			job := q.jobs[0]
+			q.jobs[0] = Job{}
+			q.jobs = q.jobs[1:]
+			q.mu.Unlock()
+			return job, nil
+		}
+		ready := q.ready
+		q.mu.Unlock()
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return Job{}, ctx.Err()
+		}
+	}
+}
+
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// Serve pops the jobs of q and runs handler on them in p, until ctx is done or q fails. It pops a job only when
+// a worker is free to run it, so that the jobs not started yet stay in q. The errors returned by handler are sent to
+// Errors, labeled with the name of the job. The jobs popped but skipped because ctx is done are pushed back to q.
+// Serve returns the error that stopped it, once the jobs it popped end.
+func (p *Pool) Serve(ctx context.Context, q Queue, handler func(ctx context.Context, job Job) error) error {
+	p.mu.Lock()
+	sem := NewSemaphore(p.concurrency)
+	p.mu.Unlock()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		if err := sem.Acquire(ctx); err != nil {
+			return err
+		}
+		job, err := q.Pop(ctx)
+		if err != nil {
+			sem.Release()
+			return err
+		}
+		wg.Add(1)
+		started := false
+		err = p.push(&task{
+			ctx:   ctx,
+			label: job.Name,
+			fn: func(ctx context.Context) error {
+				started = true
+				return handler(ctx, job)
+			},
+			cleanup: func() {
+				if !started {
+					q.Push(job)
+				}
+				sem.Release()
+				wg.Done()
+			},
+		})
+		if err != nil {
+			q.Push(job)
+			sem.Release()
+			wg.Done()
+			return err
+		}
+	}
+}
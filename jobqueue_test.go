@@ -0,0 +1,62 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	q := NewMemoryQueue()
+	for i := range 10 {
+		q.Push(Job{Name: "job" + strconv.Itoa(i), Payload: []byte{byte(i)}})
+	}
+	p := New(3)
+	errs := drain(p)
+	ctx, cancel := context.WithCancel(context.Background())
+	var handled atomic.Int32
+	var pk peak
+	err := p.Serve(ctx, q, func(_ context.Context, job Job) error {
+		pk.run(time.Millisecond)
+		if handled.Add(1) == 10 {
+			cancel()
+		}
+		if job.Payload[0] == 7 {
+			return errors.New("seven")
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Serve returned %v, want the cancellation of its context", err)
+	}
+	if handled.Load() != 10 || q.Len() != 0 {
+		t.Errorf("handled %d jobs leaving %d in the queue, want all of them handled", handled.Load(), q.Len())
+	}
+	if pk.high > 3 {
+		t.Errorf("%d jobs ran at the same time, want at most 3", pk.high)
+	}
+	p.Wait()
+	var te *TaskError
+	if got := errs(); len(got) != 1 || !errors.As(got[0], &te) || te.Label != "job7" {
+		t.Errorf("got errors %v, want the failure of job7", got)
+	}
+}
+
+func TestMemoryQueuePopWaits(t *testing.T) {
+	q := NewMemoryQueue()
+	go func() {
+		time.Sleep(time.Millisecond)
+		q.Push(Job{Name: "late"})
+	}()
+	if job, err := q.Pop(context.Background()); err != nil || job.Name != "late" {
+		t.Errorf("got %v, %v, want the job pushed later", job, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := q.Pop(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v popping an empty queue with a cancelled context", err)
+	}
+}
@@ -0,0 +1,76 @@
+package cc
+
+// keyState tracks the tasks sharing a key, see RunKeyed, or a class, see RunClass
+type keyState struct {
+	limit    int     // the number of tasks allowed in flight
+	inflight int     // the tasks that are queued, running or waiting for a retry
+	waiting  []*task // the tasks waiting for the ones in flight, in submission order
+}
+
+// WithPerKeyLimit lets up to n functions with the same key run at the same time, instead of one, see RunKeyed.
+// They still start in submission order, and the concurrency of the pool still applies.
+func WithPerKeyLimit(n int) Option {
+	return func(p *Pool) {
+		p.keyLimit = n
+	}
+}
+
+// queued returns the number of tasks waiting for a worker, or for their key or class. It must be called with p.mu held.
+func (p *Pool) queued() int {
+	return p.queue.Len() + p.keyQueued
+}
+
+// acquireKey takes the key of t, so that t can be queued. If another task with the same key is in flight t is put
+// aside instead, and acquireKey returns false. It must be called with p.mu held.
+func (p *Pool) acquireKey(t *task) bool {
+	ks := p.keys[t.key]
+	if ks == nil {
+		ks = &keyState{limit: max(p.keyLimit, 1)}
+		p.keys[t.key] = ks
+	}
+	return p.admit(ks, t)
+}
+
+// releaseKey releases the key of a task that is done, queueing the next task with the same key if any.
+// It must be called with p.mu held.
+func (p *Pool) releaseKey(key string) {
+	ks := p.keys[key]
+	p.releaseSlot(ks)
+	if ks.inflight == 0 {
+		delete(p.keys, key)
+	}
+}
+
+// admit takes a slot of ks for t, or puts t aside if they are all taken and returns false.
+// It must be called with p.mu held.
+func (p *Pool) admit(ks *keyState, t *task) bool {
+	if ks.inflight >= ks.limit {
+		ks.waiting = append(ks.waiting, t)
+		p.keyQueued++
+		return false
+	}
+	ks.inflight++
+	return true
+}
+
+// releaseSlot frees the slot of ks taken by a task that is done, queueing the next task waiting for it if any.
+// It must be called with p.mu held.
+func (p *Pool) releaseSlot(ks *keyState) {
+	ks.inflight--
+	if len(ks.waiting) == 0 || ks.inflight >= ks.limit { // still beyond the limit after a steal, see WithWorkStealing
+		return
+	}
+	p.queueWaiting(ks)
+	p.wakeWorker()
+}
+
+// queueWaiting queues the first task waiting for a slot of ks, taking the slot. It must be called with p.mu held.
+func (p *Pool) queueWaiting(ks *keyState) {
+	next := ks.waiting[0]
+	ks.waiting[0] = nil
+	ks.waiting = ks.waiting[1:]
+	p.keyQueued--
+	ks.inflight++
+	next.queued = p.clock.Now()
+	p.queue.push(next)
+}
@@ -0,0 +1,49 @@
+package cc
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// goroutines counts the goroutines started by the pools and still running
+var goroutines atomic.Int64
+
+// goTracked runs fn in a new goroutine, counted until it returns
+func goTracked(fn func()) {
+	goroutines.Add(1)
+	go func() {
+		defer goroutines.Add(-1)
+		fn()
+	}()
+}
+
+// TestingT is the part of testing.TB used by VerifyNoLeaks, so that the package doesn't import testing
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// VerifyNoLeaks fails t if goroutines started by the given pools are still running, after giving them a second to
+// exit. Without pools it checks the goroutines of all the pools of the process, groups and pipelines included, so it's
+// then unfit for the tests running in parallel with others using pools. It's meant to be deferred in tests, with their
+// *testing.T, once the pools they use are waited on, e.g. with WaitErr or Stop.
+func VerifyNoLeaks(t TestingT, pools ...*Pool) {
+	t.Helper()
+	running := func() int64 {
+		if len(pools) == 0 {
+			return goroutines.Load()
+		}
+		var n int64
+		for _, p := range pools {
+			n += p.goroutines.Load()
+		}
+		return n
+	}
+	deadline := time.Now().Add(time.Second)
+	for running() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := running(); n > 0 {
+		t.Errorf("cc: %d goroutines of pools still running", n)
+	}
+}
@@ -0,0 +1,58 @@
+package cc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestVerifyNoLeaks(t *testing.T) {
+	p := New(2)
+	p.Run(func() {})
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	VerifyNoLeaks(t)
+}
+
+// recordingT records the failures reported to it
+type recordingT struct {
+	errs []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errs = append(t.errs, fmt.Sprintf(format, args...))
+}
+
+func TestVerifyNoLeaksLeaking(t *testing.T) {
+	release := make(chan struct{})
+	p := New(2)
+	p.Run(func() { <-release })
+	var rt recordingT
+	VerifyNoLeaks(&rt)
+	close(release)
+	p.WaitErr()
+	if len(rt.errs) != 1 {
+		t.Errorf("got failures %q, want the goroutines of the pool reported", rt.errs)
+	}
+}
+
+func TestVerifyNoLeaksPools(t *testing.T) {
+	release := make(chan struct{})
+	leaking, done := New(2), New(2)
+	leaking.Run(func() { <-release })
+	done.Run(func() {})
+	if err := done.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	VerifyNoLeaks(t, done)
+	var rt recordingT
+	VerifyNoLeaks(&rt, done, leaking)
+	close(release)
+	leaking.WaitErr()
+	if len(rt.errs) != 1 {
+		t.Errorf("got failures %q, want the goroutines of the leaking pool reported", rt.errs)
+	}
+	VerifyNoLeaks(t, leaking)
+}
@@ -0,0 +1,262 @@
+package cc
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrPoolClosed is returned when submitting a function to a pool after Wait or Stop
+var ErrPoolClosed = errors.New("cc: pool closed")
+
+// poolState is the stage of the life of a pool, that only moves forward
+type poolState int
+
+const (
+	stateOpen         poolState = iota // accepting new functions
+	stateClosing                       // Wait or Stop was called, the functions submitted before end
+	stateShuttingDown                  // all the functions ended and the workers exited, the OnShutdown callbacks run
+	stateOver                          // Errors and the channels of the pool are closed, see Done
+)
+
+// advance moves the pool to s, and reports whether it did: a pool never goes back to a previous stage, nor enters
+// the same stage twice, so that only the first of concurrent calls to Wait closes the pool
+func (p *Pool) advance(s poolState) bool {
+	if s <= p.state {
+		return false
+	}
+	if s != p.state+1 {
+		panic(fmt.Sprintf("cc: pool going from stage %d to %d", p.state, s))
+	}
+	p.state = s
+	return true
+}
+
+// Closed reports whether the pool stopped accepting new functions, because Wait or Stop was called
+func (p *Pool) Closed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state != stateOpen
+}
+
+// Done returns a channel closed once the pool is over: Wait or Stop was called, all the functions ended, and Errors
+// was closed. It lets the pool be waited on in a select, alongside contexts and other channels. The errors must still
+// be consumed for the pool to get there.
+func (p *Pool) Done() <-chan struct{} {
+	return p.finished
+}
+
+// Stop shuts the pool down gracefully: it stops accepting new functions, like Wait does, and blocks until the functions
+// already submitted end. If ctx is done first, the context of the pool is cancelled so that the queued functions are
+// skipped and the running ones are asked to stop, and Stop returns the error of ctx without waiting for them.
+// Errors must still be consumed while Stop is waiting.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.Wait()
+	select {
+	case <-p.finished:
+		return nil
+	case <-ctx.Done():
+		p.cancelWith(ErrStopped, context.Cause(ctx))
+		return ctx.Err()
+	}
+}
+
+// OnShutdown registers fn to be called when the pool shuts down, after Wait or Stop, once all the functions ended.
+// The callbacks are called in the order they were registered, while the context of the pool is still alive unless
+// Stop gave up waiting, and before Errors is closed: they can't submit new functions, but may persist checkpoints.
+// If the pool is shutting down already, fn is called right away instead.
+func (p *Pool) OnShutdown(fn func()) {
+	p.mu.Lock()
+	if p.state >= stateShuttingDown {
+		p.mu.Unlock()
+		fn()
+		return
+	}
+	p.onShutdown = append(p.onShutdown, fn)
+	p.mu.Unlock()
+}
+
+// WaitBatch blocks until all the functions submitted so far end, collecting the errors sent to Errors meanwhile,
+// and returns them joined with errors.Join, or nil if there was none. Unlike Wait the pool stays open, so that
+// long-lived pools can process their work in batches. Nobody else must consume Errors when using WaitBatch.
+func (p *Pool) WaitBatch() error {
+	return errors.Join(p.Flush(context.Background())...)
+}
+
+// Flush is like WaitBatch, but it returns the errors as a slice, and stops waiting when ctx is done. The functions
+// still running then are not interrupted, and their errors are left in Errors for the next Flush.
+func (p *Pool) Flush(ctx context.Context) []error {
+	idle := p.idle()
+	var errs []error
+	for {
+		select {
+		case err, ok := <-p.Errors:
+			if !ok {
+				return errs
+			}
+			if err != ErrDone {
+				errs = append(errs, err)
+			}
+		case <-idle:
+			// the errors sent before the last function ended may still be in the buffer of Errors
+			for {
+				select {
+				case err, ok := <-p.Errors:
+					if !ok {
+						return errs
+					}
+					if err != ErrDone {
+						errs = append(errs, err)
+					}
+				default:
+					return errs
+				}
+			}
+		case <-ctx.Done():
+			return errs
+		}
+	}
+}
+
+// DrainErrors consumes Errors until it is closed, once Wait or Stop was called and all the functions ended, or until
+// ctx is done, and returns the errors collected. If ctx is done first the errors sent afterwards are discarded until
+// Errors is closed, so that returning early doesn't leave the workers blocked on Errors. Unlike WaitErr it doesn't
+// call Wait, and nobody else must consume Errors when using DrainErrors.
+func (p *Pool) DrainErrors(ctx context.Context) []error {
+	var errs []error
+	for {
+		select {
+		case err, ok := <-p.Errors:
+			if !ok {
+				return errs
+			}
+			if err != ErrDone {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			p.goTracked(func() {
+				for range p.Errors {
+				}
+			})
+			return errs
+		}
+	}
+}
+
+// idle returns a channel closed as soon as no task is pending
+func (p *Pool) idle() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c := make(chan struct{})
+	if p.pending == 0 {
+		close(c)
+		return c
+	}
+	p.idleWaiters = append(p.idleWaiters, c)
+	return c
+}
+
+// RunningTask describes a function running in a pool
+type RunningTask struct {
+	Label string
+	Start time.Time
+
+	goroutine uint64 // the ID of the goroutine running the function, see Dump
+}
+
+// StillRunningError is returned by WaitContext when its context is done before all the functions end
+type StillRunningError struct {
+	Err     error // the error of the context
+	Running []RunningTask
+}
+
+func (e *StillRunningError) Error() string {
+	labels := make([]string, len(e.Running))
+	for i, t := range e.Running {
+		labels[i] = cmp.Or(t.Label, "(unlabeled)")
+	}
+	return fmt.Sprintf("cc: %d functions still running: %s: %s", len(e.Running), strings.Join(labels, ", "), e.Err)
+}
+
+func (e *StillRunningError) Unwrap() error {
+	return e.Err
+}
+
+// WaitContext is like WaitErr, but it stops waiting when ctx is done. In that case the returned error also holds
+// a StillRunningError, listing the functions still running, that wraps the error of ctx. The functions are not
+// interrupted, and the rest of their errors can still be consumed from Errors.
+func (p *Pool) WaitContext(ctx context.Context) error {
+	p.Wait()
+	var errs []error
+	for {
+		select {
+		case err, ok := <-p.Errors:
+			if !ok {
+				if p.collectErrors {
+					errs = p.Errs()
+				}
+				return errors.Join(errs...)
+			}
+			if err != ErrDone {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			if p.collectErrors {
+				errs = p.Errs()
+			}
+			errs = append(errs, &StillRunningError{Err: ctx.Err(), Running: p.runningTasks()})
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// WaitWithProgress is like WaitErr, but it calls fn with the Stats of the pool every interval while waiting, and once
+// more when all the functions ended, so that a command can print its progress, e.g. "42/100 done, 3 failed".
+func (p *Pool) WaitWithProgress(interval time.Duration, fn func(Stats)) error {
+	p.Wait()
+	ticks, stop := tick(p.clock, interval)
+	defer stop()
+	var errs []error
+	for {
+		select {
+		case err, ok := <-p.Errors:
+			if !ok {
+				fn(p.Stats())
+				if p.collectErrors {
+					errs = p.Errs()
+				}
+				return errors.Join(errs...)
+			}
+			if err != ErrDone {
+				errs = append(errs, err)
+			}
+		case <-ticks:
+			fn(p.Stats())
+		}
+	}
+}
+
+// WaitTimeout is like WaitContext, with a context expiring after d
+func (p *Pool) WaitTimeout(d time.Duration) error {
+	ctx, cancel := withClockTimeout(context.Background(), p.clock, d)
+	defer cancel()
+	return p.WaitContext(ctx)
+}
+
+// runningTasks returns the functions running, the oldest first
+func (p *Pool) runningTasks() []RunningTask {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tasks := make([]RunningTask, 0, len(p.running))
+	for t, start := range p.running {
+		tasks = append(tasks, RunningTask{Label: t.label, Start: start, goroutine: t.goroutine})
+	}
+	slices.SortFunc(tasks, func(a, b RunningTask) int {
+		return a.Start.Compare(b.Start)
+	})
+	return tasks
+}
@@ -0,0 +1,156 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStop(t *testing.T) {
+	p := New(2)
+	errs := drain(p)
+	var ran atomic.Int32
+	for range 10 {
+		p.Run(func() { ran.Add(1) })
+	}
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran.Load() != 10 {
+		t.Errorf("Stop returned after %d functions out of 10", ran.Load())
+	}
+	if err := p.Run(func() {}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("got %v running a function after Stop, want ErrPoolClosed", err)
+	}
+	errs()
+}
+
+func TestStopCancelled(t *testing.T) {
+	p := New(1)
+	errs := drain(p)
+	p.RunCtx(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done() // only returning once Stop gave up
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Stop(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Stop returned %v, want the error of its context", err)
+	}
+	errs()
+}
+
+// submitting calls Run from several goroutines until the pool refuses the functions, counting the ones accepted
+// and the ones that ran, and fails the test on any other error
+func submitting(t *testing.T, p *Pool, accepted, ran *atomic.Int64) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := p.Run(func() { ran.Add(1) })
+				if errors.Is(err, ErrPoolClosed) {
+					return
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				accepted.Add(1)
+			}
+		}()
+	}
+	return &wg
+}
+
+func TestLifecycleRunWaitStop(t *testing.T) {
+	for range 10 {
+		p := New(4)
+		var accepted, ran atomic.Int64
+		var shutdowns atomic.Int32
+		p.OnShutdown(func() { shutdowns.Add(1) })
+		wg := submitting(t, p, &accepted, &ran)
+		go func() {
+			for range p.Errors {
+			}
+		}()
+		time.Sleep(time.Millisecond)
+		var closers sync.WaitGroup
+		for i := range 4 {
+			closers.Add(1)
+			go func() {
+				defer closers.Done()
+				if i%2 == 0 {
+					p.Wait()
+					return
+				}
+				if err := p.Stop(context.Background()); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		closers.Wait()
+		wg.Wait()
+		<-p.Done()
+		if accepted.Load() != ran.Load() {
+			t.Fatalf("%d functions accepted but %d ran", accepted.Load(), ran.Load())
+		}
+		if shutdowns.Load() != 1 {
+			t.Fatalf("the shutdown callback was called %d times", shutdowns.Load())
+		}
+	}
+}
+
+func TestLifecycleStopGivingUp(t *testing.T) {
+	p := New(2)
+	var accepted, ran atomic.Int64
+	wg := submitting(t, p, &accepted, &ran)
+	go func() {
+		for range p.Errors {
+		}
+	}()
+	time.Sleep(time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.Stop(ctx)
+	p.Wait()
+	wg.Wait()
+	select {
+	case <-p.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the pool never ended after Stop gave up")
+	}
+	if ran.Load() > accepted.Load() {
+		t.Errorf("%d functions accepted but %d ran", accepted.Load(), ran.Load())
+	}
+	if err := p.Run(func() {}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("got %v after the end of the pool, want ErrPoolClosed", err)
+	}
+}
+
+func TestLifecycleOnShutdownLate(t *testing.T) {
+	p := New(1)
+	p.Wait()
+	<-p.Done()
+	called := false
+	p.OnShutdown(func() { called = true })
+	if !called {
+		t.Error("the callback registered once the pool shut down was never called")
+	}
+	if !p.Closed() {
+		t.Error("the pool isn't closed")
+	}
+}
+
+func TestWaitContextErrorCollector(t *testing.T) {
+	p := New(2, WithErrorCollector())
+	failure := errors.New("failure")
+	p.Go(func() error { return failure })
+	if err := p.WaitContext(context.Background()); !errors.Is(err, failure) {
+		t.Errorf("got %v, want the collected failure", err)
+	}
+}
@@ -0,0 +1,45 @@
+package cc
+
+import "context"
+
+// Limiter limits the functions running at the same time, on top of the concurrency of a pool, see WithLimiter.
+// Semaphore is a Limiter, and implementations may be shared with code outside of the pools, or be distributed,
+// e.g. backed by Redis. Implementations must be safe for concurrent use.
+type Limiter interface {
+	// Acquire blocks until a function can run. It returns an error if ctx is done first, or if the limiter fails.
+	Acquire(ctx context.Context) error
+	// Release lets another function run, once a function that acquired the limiter is over
+	Release()
+}
+
+var _ Limiter = (*Semaphore)(nil)
+
+// WithLimiter makes each function of the pool acquire l once it has a worker, right before running, and release it
+// once it ended, so that l limits the pool alongside the rest of its features. The weight of the functions is not
+// passed on, see RunWeighted. Several limiters can be given, with several WithLimiter too: they are acquired in
+// order, and released in reverse order. A function whose context is done while acquiring is skipped, and a function
+// failing to acquire a limiter otherwise is skipped too, failing with the error of Acquire.
+func WithLimiter(limiters ...Limiter) Option {
+	return func(p *Pool) {
+		p.limiters = append(p.limiters, limiters...)
+	}
+}
+
+// acquireLimiters acquires the limiters of the pool for a function running with ctx. It returns a func releasing
+// them, and the error of the limiter that failed, in which case the ones acquired already are released.
+func (p *Pool) acquireLimiters(ctx context.Context) (release func(), err error) {
+	acquired := 0
+	release = func() {
+		for i := acquired - 1; i >= 0; i-- {
+			p.limiters[i].Release()
+		}
+	}
+	for _, l := range p.limiters {
+		if err := l.Acquire(ctx); err != nil {
+			release()
+			return nil, err
+		}
+		acquired++
+	}
+	return release, nil
+}
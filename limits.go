@@ -0,0 +1,55 @@
+package cc
+
+import "fmt"
+
+// WithLimits gives the pool named classes of functions, each one with its own limit of functions running at the same
+// time, in addition to the concurrency of the pool, e.g. cc.WithLimits(map[string]int{"network": 8, "disk": 2}).
+// Functions are run in a class with RunClass.
+func WithLimits(limits map[string]int) Option {
+	return func(p *Pool) {
+		p.classes = make(map[string]*keyState, len(limits))
+		for class, limit := range limits {
+			p.classes[class] = &keyState{limit: max(limit, 1)}
+		}
+	}
+}
+
+// WithWorkStealing lets the idle workers run the functions waiting for the limit of their class, see WithLimits, when
+// the pool has nothing else to run, taking them from the class with the most functions waiting. It keeps the pool busy
+// when the workload is skewed towards a class, the limits then only holding while there are other functions to run.
+// The functions with a key are never stolen, as they must run one after the other, see RunKeyed.
+func WithWorkStealing() Option {
+	return func(p *Pool) {
+		p.stealing = true
+	}
+}
+
+// RunClass is like Run, but fn counts against the limit of class, given with WithLimits. The functions of a class waiting
+// for their limit don't hold a worker. It returns an error if the class is unknown.
+func (p *Pool) RunClass(class string, fn func()) error {
+	if p.classes[class] == nil {
+		return fmt.Errorf("cc: unknown class %q", class)
+	}
+	t := plainTask(fn)
+	t.class = class
+	return p.push(t)
+}
+
+// steal queues the first function waiting for its class, from the class with the most functions waiting, if the pool
+// has nothing else to run and a free slot, see WithWorkStealing. It must be called with p.mu held.
+func (p *Pool) steal() bool {
+	if !p.stealing || p.paused || p.queue.Len() > 0 || p.used >= max(p.concurrency-p.throttled, 1) {
+		return false
+	}
+	var victim *keyState
+	for _, ks := range p.classes {
+		if len(ks.waiting) > 0 && (victim == nil || len(ks.waiting) > len(victim.waiting)) {
+			victim = ks
+		}
+	}
+	if victim == nil {
+		return false
+	}
+	p.queueWaiting(victim)
+	return true
+}
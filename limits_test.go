@@ -0,0 +1,47 @@
+package cc
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkStealing(t *testing.T) {
+	for _, stealing := range []bool{false, true} {
+		opts := []Option{WithLimits(map[string]int{"a": 1, "b": 1})}
+		if stealing {
+			opts = append(opts, WithWorkStealing())
+		}
+		p := New(2, opts...)
+		var pk peak
+		for range 6 {
+			p.RunClass("a", func() { pk.run(5 * time.Millisecond) }) // nothing for b, leaving a slot free
+		}
+		if err := p.WaitErr(); err != nil {
+			t.Fatal(err)
+		}
+		if want := map[bool]int{false: 1, true: 2}[stealing]; pk.high != want {
+			t.Errorf("stealing %v: %d functions of a ran at the same time, want %d", stealing, pk.high, want)
+		}
+	}
+}
+
+func TestWorkStealingBusy(t *testing.T) {
+	p := New(2, WithLimits(map[string]int{"a": 1, "b": 1}), WithWorkStealing())
+	release := make(chan struct{})
+	p.RunClass("b", func() { <-release }) // b keeps its slot while the functions of a run
+	var pk peak
+	var ran sync.WaitGroup
+	ran.Add(6)
+	for range 6 {
+		p.RunClass("a", func() { defer ran.Done(); pk.run(time.Millisecond) })
+	}
+	ran.Wait()
+	close(release)
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	if pk.high != 1 {
+		t.Errorf("%d functions of a ran at the same time, want 1 without a free slot to steal", pk.high)
+	}
+}
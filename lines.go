@@ -0,0 +1,48 @@
+package cc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Lines reads the lines of r and calls fn on each of them, with at most concurrency calls at the same time. The reading
+// waits while concurrency lines are already waiting, so that the memory stays bounded on huge inputs. The lines are
+// given without their line ending, and the errors of fn are labeled with the line number, starting from 1.
+// Lines stops reading when ctx is done, and returns the errors of fn and of the reading joined with errors.Join, along
+// with the error of ctx if it was cancelled. It stops reading as well if the pool refuses a line, and returns the reason
+// among the errors. The options configure the pool running fn: with WithOrdered the errors are reported in the order of
+// the lines.
+func Lines(ctx context.Context, r io.Reader, concurrency int, fn func(line string) error, opts ...Option) error {
+	p := NewWithContext(ctx, concurrency, append(opts[:len(opts):len(opts)], WithQueueSize(concurrency), WithErrorCollector())...)
+	br := bufio.NewReader(r)
+	var errs []error
+	for n := 1; ctx.Err() == nil; n++ {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			if err := p.push(&task{label: "line " + strconv.Itoa(n), fn: func(context.Context) error {
+				return fn(line)
+			}}); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				errs = append(errs, err)
+			}
+			break
+		}
+	}
+	p.Wait()
+	<-p.finished
+	errs = append(errs, p.Errs()...)
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
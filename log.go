@@ -0,0 +1,67 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// WithLogger makes the pool log the start, end, failure, retry and panic of each function at debug level on l,
+// with the name of the pool, the label and attempt of the function and its duration as attributes.
+func WithLogger(l *slog.Logger) Option {
+	return func(p *Pool) {
+		p.logger = l
+	}
+}
+
+func (p *Pool) logEnabled() bool {
+	return p.logger != nil && p.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+func (p *Pool) logStart(t *task) {
+	if !p.logEnabled() {
+		return
+	}
+	p.logger.LogAttrs(context.Background(), slog.LevelDebug, "cc: task started",
+		slog.String("pool", p.name),
+		slog.String("task", t.label),
+		slog.Int("attempt", t.attempt+1))
+}
+
+func (p *Pool) logEnd(info TaskInfo, retried bool) {
+	if !p.logEnabled() {
+		return
+	}
+	msg := "cc: task finished"
+	var perr *PanicError
+	switch {
+	case errors.As(info.Err, &perr):
+		msg = "cc: task panicked"
+	case info.Err != nil && retried:
+		msg = "cc: task failed, retrying"
+	case info.Err != nil:
+		msg = "cc: task failed"
+	}
+	attrs := []slog.Attr{
+		slog.String("pool", info.Pool),
+		slog.String("task", info.Label),
+		slog.Int("attempt", info.Attempt),
+		slog.Duration("duration", info.Duration),
+	}
+	if info.Err != nil {
+		attrs = append(attrs, slog.Any("error", info.Err))
+	}
+	if info.Meta != nil {
+		attrs = append(attrs, slog.Any("meta", info.Meta))
+	}
+	p.logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
+
+func (p *Pool) logSkip(t *task) {
+	if !p.logEnabled() {
+		return
+	}
+	p.logger.LogAttrs(context.Background(), slog.LevelDebug, "cc: task skipped",
+		slog.String("pool", p.name),
+		slog.String("task", t.label))
+}
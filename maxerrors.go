@@ -0,0 +1,37 @@
+package cc
+
+import "errors"
+
+// ErrTooManyFailures is the error of the functions skipped by a pool that reached its limit of errors, see WithMaxErrors
+var ErrTooManyFailures = errors.New("cc: too many failures")
+
+// WithMaxErrors makes the pool stop launching the queued functions once n errors have been reported, so that a bulk
+// operation against a dead backend doesn't go through all its doomed functions. Unlike WithFailFast the running
+// functions are not cancelled. The functions skipped then fail with ErrTooManyFailures, and are counted as skipped.
+func WithMaxErrors(n int) Option {
+	return func(p *Pool) {
+		p.maxErrors = n
+	}
+}
+
+// tooManyFailures reports whether the pool reached the limit set with WithMaxErrors
+func (p *Pool) tooManyFailures() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.maxErrors > 0 && p.nerrs >= p.maxErrors
+}
+
+// abandon skips t, failing it with err
+func (p *Pool) abandon(t *task, err error) {
+	p.skipped(t)
+	p.finish(t, &TaskError{TaskInfo: TaskInfo{
+		Pool:      p.name,
+		Label:     t.label,
+		Submitted: t.submitted,
+		Attempt:   t.attempt + 1,
+		Seq:       t.id,
+		Err:       err,
+		Origin:    t.where(),
+		Meta:      t.meta,
+	}})
+}
@@ -0,0 +1,55 @@
+package cc
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMaxErrors(t *testing.T) {
+	p := New(1, WithMaxErrors(2))
+	failure := errors.New("failure")
+	var ran atomic.Int32
+	for range 10 {
+		p.Go(func() error { ran.Add(1); return failure })
+	}
+	errs := drain(p)
+	p.Wait()
+	failed, skipped := 0, 0
+	for _, err := range errs() {
+		switch {
+		case errors.Is(err, ErrTooManyFailures):
+			skipped++
+		case errors.Is(err, failure):
+			failed++
+		}
+	}
+	if ran.Load() != 2 || failed != 2 || skipped != 8 {
+		t.Errorf("ran %d functions, %d failed and %d were skipped, want 2 failures then 8 skipped",
+			ran.Load(), failed, skipped)
+	}
+	if st := p.Stats(); st.Skipped != 8 {
+		t.Errorf("counted %d functions skipped, want 8", st.Skipped)
+	}
+}
+
+func TestMaxErrorsSkippedAttempt(t *testing.T) {
+	p := New(1, WithMaxErrors(1))
+	failure := errors.New("failure")
+	p.Go(func() error { return failure })
+	p.Go(func() error { return nil })
+	var skipped *TaskError
+	p.Wait()
+	for err := range p.Errors {
+		var te *TaskError
+		if errors.As(err, &te) && errors.Is(err, ErrTooManyFailures) {
+			skipped = te
+		}
+	}
+	if skipped == nil {
+		t.Fatal("no function skipped with ErrTooManyFailures")
+	}
+	if skipped.Attempt != 1 {
+		t.Errorf("the skipped function reports attempt %d, want 1", skipped.Attempt)
+	}
+}
@@ -0,0 +1,98 @@
+package cc
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	// ErrQueueMemory is returned when submitting a function that doesn't fit in the memory of the queue, see WithQueueMemory
+	ErrQueueMemory = errors.New("cc: queue memory exhausted")
+	// ErrEvicted is the error of the functions dropped from the queue to make room for others, see EvictLowestPriority
+	ErrEvicted = errors.New("cc: evicted from the queue")
+)
+
+// Eviction is what a pool does when a function doesn't fit in the memory of its queue, see WithQueueMemory
+type Eviction int
+
+const (
+	// EvictReject refuses the function, the default
+	EvictReject Eviction = iota
+	// EvictLowestPriority drops the queued functions with a priority lower than the one of the function, the lowest
+	// and most recent first, until it fits. It's refused if that's not enough.
+	EvictLowestPriority
+)
+
+// WithQueueMemory caps the memory held by the functions waiting in the queue to limit bytes, as estimated by the
+// submitters with RunSized, so that a burst can't exhaust the memory of small devices. The functions taking the memory
+// over the limit are handled according to policy, and onEvict, which may be nil, is called with the label and the size
+// of each function refused or dropped. The refused functions get ErrQueueMemory, and the dropped ones fail with
+// ErrEvicted and are counted as skipped. The functions waiting for their key or class don't count, as they're not in
+// the queue yet. Functions can't be spilled to disk, being closures: a durable backlog is better kept in a Queue
+// backed by storage, consumed with Serve.
+func WithQueueMemory(limit int64, policy Eviction, onEvict func(label string, size int64)) Option {
+	return func(p *Pool) {
+		p.memLimit, p.eviction, p.onEvict = limit, policy, onEvict
+	}
+}
+
+// RunSized is like RunNamed, but fn is estimated to hold size bytes while it's queued, see WithQueueMemory, and it has
+// the given priority, see RunPriority
+func (p *Pool) RunSized(label string, size int64, priority int, fn func()) error {
+	t := plainTask(fn)
+	t.label, t.size, t.priority = label, size, priority
+	return p.push(t)
+}
+
+// makeRoom evicts queued tasks according to the eviction policy until t fits in the memory of the queue, and returns
+// them. It returns ErrQueueMemory if t doesn't fit anyway, evicting nothing. It must be called with p.mu held.
+func (p *Pool) makeRoom(t *task) ([]*task, error) {
+	if p.memLimit <= 0 || p.queue.mem+t.size <= p.memLimit {
+		return nil, nil
+	}
+	if p.eviction != EvictLowestPriority {
+		return nil, ErrQueueMemory
+	}
+	var victims []*task
+	freed := int64(0)
+	for p.queue.mem-freed+t.size > p.memLimit {
+		var victim *task
+		for _, q := range p.queue.tasks {
+			if q.size > 0 && q.priority < t.priority && !slices.Contains(victims, q) &&
+				(victim == nil || q.priority < victim.priority || q.priority == victim.priority && q.seq > victim.seq) {
+				victim = q
+			}
+		}
+		if victim == nil {
+			return nil, ErrQueueMemory
+		}
+		victims = append(victims, victim)
+		freed += victim.size
+	}
+	for _, v := range victims {
+		p.queue.remove(v)
+		if p.metrics != nil {
+			p.metrics.AddQueued(-1)
+		}
+	}
+	p.notFull.Broadcast()
+	return victims, nil
+}
+
+// evicted reports the tasks refused or dropped for lack of memory to onEvict, and fails the dropped ones in the
+// background
+func (p *Pool) evicted(refused *task, victims []*task) {
+	if p.onEvict != nil {
+		if refused != nil {
+			p.onEvict(refused.label, refused.size)
+		}
+		for _, v := range victims {
+			p.onEvict(v.label, v.size)
+		}
+	}
+	for _, v := range victims {
+		if !p.tryGo(func() { p.abandon(v, ErrEvicted) }) { // not to block the submitter on Errors
+			p.abandon(v, ErrEvicted)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cc
+
+import "context"
+
+// TaskOption configures a function submitted to a pool, see Meta
+type TaskOption func(*task)
+
+// Meta attaches the metadata key with value to a function, e.g. p.Run(fn, cc.Meta("device", id)), so that the
+// middlewares, the logs, the metrics and the errors of the pool can tell about it through TaskInfo.Meta, and the
+// function itself through MetaFrom, without changing its signature.
+func Meta(key string, value any) TaskOption {
+	return func(t *task) {
+		if t.meta == nil {
+			t.meta = map[string]any{}
+		}
+		t.meta[key] = value
+	}
+}
+
+// MetaFrom returns the metadata of the function run by a pool that received ctx, see Meta, or nil if it has none.
+// The map must not be modified.
+func MetaFrom(ctx context.Context) map[string]any {
+	meta, _ := ctx.Value(metaKey{}).(map[string]any)
+	return meta
+}
+
+type metaKey struct{}
+
+// with applies opts to t, and returns t
+func (t *task) with(opts []TaskOption) *task {
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
@@ -0,0 +1,60 @@
+package cc
+
+import "expvar"
+
+// Metrics receives the measurements of a pool, so that they can be exposed by any monitoring system (Prometheus,
+// expvar, ...) without this package depending on it. Implementations must be safe for concurrent use and fast,
+// since they may be called while the pool holds its internal lock.
+type Metrics interface {
+	// AddRunning adds delta to the number of functions running
+	AddRunning(delta int)
+	// AddQueued adds delta to the number of functions waiting for a worker
+	AddQueued(delta int)
+	// ObserveTask records a run of a function. Each attempt is observed when retrying.
+	ObserveTask(info TaskInfo)
+}
+
+// WithMetrics makes the pool report its measurements to m
+func WithMetrics(m Metrics) Option {
+	return func(p *Pool) {
+		p.metrics = m
+	}
+}
+
+// ExpvarMetrics is a Metrics publishing the measurements of a pool with expvar
+type ExpvarMetrics struct {
+	running  expvar.Int
+	queued   expvar.Int
+	runs     expvar.Int
+	failures expvar.Int
+	seconds  expvar.Float
+}
+
+// NewExpvarMetrics returns an ExpvarMetrics published as an expvar.Map with the given name, that must be unique
+// like for any other expvar.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	m := &ExpvarMetrics{}
+	vars := expvar.NewMap(name)
+	vars.Set("running", &m.running)
+	vars.Set("queued", &m.queued)
+	vars.Set("runs", &m.runs)
+	vars.Set("failures", &m.failures)
+	vars.Set("busy_seconds", &m.seconds)
+	return m
+}
+
+func (m *ExpvarMetrics) AddRunning(delta int) {
+	m.running.Add(int64(delta))
+}
+
+func (m *ExpvarMetrics) AddQueued(delta int) {
+	m.queued.Add(int64(delta))
+}
+
+func (m *ExpvarMetrics) ObserveTask(info TaskInfo) {
+	m.runs.Add(1)
+	if info.Err != nil {
+		m.failures.Add(1)
+	}
+	m.seconds.Add(info.Duration.Seconds())
+}
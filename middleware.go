@@ -0,0 +1,60 @@
+package cc
+
+import (
+	"context"
+	"time"
+)
+
+// Middleware wraps the runs of the functions of a pool, so that cross-cutting concerns like refreshing credentials,
+// metrics, logging or error translation can be layered without changing every function. Implementations must be safe
+// for concurrent use.
+type Middleware interface {
+	// Before is called before a run of a function, described by info. The returned context is given to the function,
+	// and the next middlewares. Returning an error fails the run with it, without calling the function.
+	Before(ctx context.Context, info TaskInfo) (context.Context, error)
+	// After is called once the run is over, with the context returned by Before. info describes the run, and err is
+	// the error returned by the function or by the next middlewares. The returned error replaces err.
+	After(ctx context.Context, info TaskInfo, err error) error
+}
+
+// WithMiddleware makes the pool wrap each run of a function with mws. The Before methods are called in the given
+// order, and the After methods in the reverse order, like nested HTTP handlers: a middleware whose Before failed, or
+// was never called, doesn't see the After call.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(p *Pool) {
+		p.middlewares = append(p.middlewares, mws...)
+	}
+}
+
+// around runs t through the middlewares of the pool
+func (p *Pool) around(ctx context.Context, t *task, start time.Time) error {
+	if len(p.middlewares) == 0 {
+		return p.call(ctx, t)
+	}
+	info := TaskInfo{
+		Pool:      p.name,
+		Label:     t.label,
+		Submitted: t.submitted,
+		Start:     start,
+		Attempt:   t.attempt + 1,
+		Seq:       t.id,
+		Meta:      t.meta,
+	}
+	ctxs := make([]context.Context, 0, len(p.middlewares))
+	var err error
+	for _, mw := range p.middlewares {
+		if ctx, err = mw.Before(ctx, info); err != nil {
+			break
+		}
+		ctxs = append(ctxs, ctx)
+	}
+	if err == nil {
+		err = p.call(ctx, t)
+	}
+	info.Duration = p.since(start)
+	for i := len(ctxs) - 1; i >= 0; i-- {
+		info.Err = err
+		err = p.middlewares[i].After(ctxs[i], info, err)
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package cc
+
+import (
+	"runtime"
+	"runtime/metrics"
+)
+
+// The CPU usage of the process above which a low-priority pool halves its concurrency, and below which it restores it
+const (
+	niceBusy    = 0.9
+	niceRelaxed = 0.7
+)
+
+// niceStale is the number of steps without a garbage collection after which a low-priority pool restores its
+// concurrency, having no clue about the CPU usage anymore
+const niceStale = 10
+
+// WithLowPriority makes the pool step aside for the rest of the process, e.g. for a background indexing pool not to
+// starve the pool serving the UI: its workers call runtime.Gosched between functions, and while the CPU available to
+// the process is more than 90% used, its own functions included, the pool runs at most half of its concurrency, at
+// least one function, until the usage goes back below 70%. The usage comes from runtime/metrics, which updates it at
+// each garbage collection: the pool reacts at the pace of the collections, and restores its concurrency if there was
+// none in the last second.
+func WithLowPriority() Option {
+	return func(p *Pool) {
+		p.lowPriority = &niceness{samples: []metrics.Sample{
+			{Name: "/cpu/classes/idle:cpu-seconds"},
+			{Name: "/cpu/classes/total:cpu-seconds"},
+		}}
+	}
+}
+
+type niceness struct {
+	samples     []metrics.Sample
+	idle, total float64 // the CPU seconds read at the previous step, 0 before the first one
+	stale       int     // the number of steps since the metrics were last updated
+}
+
+// niceStep measures the CPU usage of the process since the previous step, and throttles the pool if it's too high
+func (p *Pool) niceStep() {
+	n := p.lowPriority
+	metrics.Read(n.samples)
+	idle, total := n.samples[0].Value.Float64(), n.samples[1].Value.Float64()
+	elapsed := total - n.total
+	if elapsed <= 0 {
+		// the runtime updates the CPU metrics at each garbage collection only
+		if n.stale++; n.stale == niceStale {
+			p.relax()
+		}
+		return
+	}
+	n.stale = 0
+	usage := 1 - (idle-n.idle)/elapsed
+	first := n.total == 0
+	n.idle, n.total = idle, total
+	if first {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case usage > niceBusy:
+		p.throttled = p.concurrency - max(p.concurrency/2, 1)
+	case usage < niceRelaxed:
+		p.relaxLocked()
+	}
+}
+
+// relax lifts the throttling of WithLowPriority
+func (p *Pool) relax() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.relaxLocked()
+}
+
+// relaxLocked is relax with p.mu held
+func (p *Pool) relaxLocked() {
+	if p.throttled > 0 {
+		p.throttled = 0
+		p.notEmpty.Broadcast()
+	}
+}
+
+// yieldBetweenTasks lets the other goroutines run before the worker picks up its next function, see WithLowPriority
+func (p *Pool) yieldBetweenTasks() {
+	if p.lowPriority != nil {
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,69 @@
+package cc
+
+import (
+	"context"
+	"time"
+)
+
+// RunOnce queues fn like Submit does, unless a function submitted with the same key is still queued or running:
+// then it returns the handle on that one instead, so that concurrent callers share a single execution and its result.
+// Once the function is done the key is free again, and the next call runs fn anew. Canceling the returned handle
+// cancels the function for all the callers sharing it. With WithMemoize the key stays taken by a function that
+// succeeded for a while, and the next calls get its handle, done already, without running anything.
+func (p *Pool) RunOnce(key string, fn func() (any, error)) *Task[any] {
+	p.mu.Lock()
+	if t := p.flights[key]; t != nil {
+		p.mu.Unlock()
+		return t
+	}
+	if m := p.memos[key]; m != nil {
+		p.mu.Unlock()
+		return m.t
+	}
+	t := newTask(p, func(context.Context) (any, error) {
+		return fn()
+	})
+	t.task.label = key
+	t.task.onDone = func(err error) {
+		p.mu.Lock()
+		delete(p.flights, key)
+		if err == nil && p.memoTTL > 0 {
+			p.memoize(key, t)
+		}
+		p.mu.Unlock()
+		t.finish(err)
+	}
+	p.flights[key] = t
+	p.mu.Unlock()
+
+	if err := p.push(t.task); err != nil {
+		t.task.onDone(err)
+	}
+	return t
+}
+
+// WithMemoize makes RunOnce keep the results of the functions that succeed for ttl, so that the calls with the same key
+// get them without running the functions again nor taking a worker. The errors are not kept.
+func WithMemoize(ttl time.Duration) Option {
+	return func(p *Pool) {
+		p.memoTTL = ttl
+	}
+}
+
+// Forget drops the result kept for key by WithMemoize, so that the next call to RunOnce with key runs its function
+func (p *Pool) Forget(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if m := p.memos[key]; m != nil {
+		p.forget(m)
+	}
+}
+
+// expire drops the result kept by m, unless it was replaced by a newer one meanwhile
+func (p *Pool) expire(m *memo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.memos[m.key] == m {
+		delete(p.memos, m.key)
+	}
+}
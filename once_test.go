@@ -0,0 +1,60 @@
+package cc
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunOnceShared(t *testing.T) {
+	p := New(2)
+	errs := drain(p)
+	release := make(chan struct{})
+	var runs atomic.Int32
+	first := p.RunOnce("k", func() (any, error) { runs.Add(1); <-release; return "first", nil })
+	second := p.RunOnce("k", func() (any, error) { runs.Add(1); return "second", nil })
+	if first != second {
+		t.Error("the second call with the same key got its own handle")
+	}
+	close(release)
+	if v, err := second.Result(); v != "first" || err != nil {
+		t.Errorf("got %v, %v, want the result of the first call", v, err)
+	}
+	if v, _ := p.RunOnce("k", func() (any, error) { return "third", nil }).Result(); v != "third" {
+		t.Errorf("got %v once the key was free, want a new execution", v)
+	}
+	if runs.Load() != 1 {
+		t.Errorf("ran %d functions sharing the key, want 1", runs.Load())
+	}
+	p.Wait()
+	errs()
+}
+
+func TestRunOnceAfterCancel(t *testing.T) {
+	p := New(1)
+	busy, started := make(chan struct{}), make(chan struct{})
+	p.Run(func() { close(started); <-busy })
+	<-started
+	first := p.RunOnce("key", func() (any, error) { return 1, nil })
+	first.Cancel()
+	if _, err := first.Result(); !errors.Is(err, ErrCanceled) {
+		t.Errorf("the cancelled task returned %v, want ErrCanceled", err)
+	}
+	close(busy)
+	second := p.RunOnce("key", func() (any, error) { return 2, nil })
+	if second == first {
+		t.Fatal("RunOnce returned the cancelled task again")
+	}
+	if v, err := second.Result(); v != 2 || err != nil {
+		t.Errorf("RunOnce returned %v, %v, want 2", v, err)
+	}
+	p.mu.Lock()
+	flights := len(p.flights)
+	p.mu.Unlock()
+	if flights != 0 {
+		t.Errorf("%d flights left", flights)
+	}
+	if err := p.WaitErr(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,111 @@
+package cc
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures optional behaviors of a Pool. Options are passed to the constructors, e.g. cc.New(4, cc.WithPanicHandler(h))
+type Option func(*Pool)
+
+// WithName gives a name to the pool, so that the errors, stats and panics coming from it can be told apart from
+// the ones of other pools.
+func WithName(name string) Option {
+	return func(p *Pool) {
+		p.name = name
+	}
+}
+
+// WithContext binds the pool to ctx, like NewWithContext does
+func WithContext(ctx context.Context) Option {
+	return func(p *Pool) {
+		p.ctx = ctx
+	}
+}
+
+// WithQueueSize bounds the queue of the pool to n functions waiting for a worker, like NewBounded does.
+// A size of 0 leaves the queue unbounded.
+func WithQueueSize(n int) Option {
+	return func(p *Pool) {
+		p.queueSize = max(n, 0)
+	}
+}
+
+// WithPanicHandler makes the pool call fn with the recovered value when a function panics, instead of sending a PanicError to Errors.
+func WithPanicHandler(fn func(any)) Option {
+	return func(p *Pool) {
+		p.panicHandler = fn
+	}
+}
+
+// WithFailFast makes the pool stop at the first error: the context of the pool is cancelled, so the functions that
+// haven't started yet are skipped and the running ones see the cancellation, with ErrSiblingFailed as its cause, see
+// CancelError. The error is available with FirstError.
+func WithFailFast() Option {
+	return func(p *Pool) {
+		p.failFast = true
+	}
+}
+
+// WithRetry makes the pool run a function up to attempts times, as long as it returns an error. Before each new
+// attempt the function waits for the delay returned by backoff (which may be nil), without holding a worker.
+// Only the error of the last attempt is sent to Errors. Panics and the errors marked with Permanent are not retried.
+func WithRetry(attempts int, backoff Backoff) Option {
+	return func(p *Pool) {
+		p.attempts = attempts
+		p.backoff = backoff
+	}
+}
+
+// WithOnComplete makes the pool call fn each time a function ends, from the worker that ran it, before its error
+// is sent to Errors. With WithRetry fn is called once, after the last attempt.
+func WithOnComplete(fn func(TaskInfo)) Option {
+	return func(p *Pool) {
+		p.onComplete = fn
+	}
+}
+
+// WithWaitThreshold makes the pool call fn, from the worker, when a function waited more than d in the queue before
+// starting. Long waits are the sign that the concurrency of the pool is too low for its load.
+func WithWaitThreshold(d time.Duration, fn func(label string, wait time.Duration)) Option {
+	return func(p *Pool) {
+		p.waitThreshold = d
+		p.onSlowWait = fn
+	}
+}
+
+// WithSlowTaskThreshold makes the pool call fn, from its own goroutine, when a function has been running for more than d,
+// without cancelling it. It's called once per attempt, while the function is still running, so that stalled functions
+// can be spotted before they hang the whole pool.
+func WithSlowTaskThreshold(d time.Duration, fn func(label string, elapsed time.Duration)) Option {
+	return func(p *Pool) {
+		p.slowThreshold = d
+		p.onSlowTask = fn
+	}
+}
+
+// WithErrorBuffer gives a buffer of n errors to Errors, so that the functions can fail without blocking until the
+// buffer is full, even if Errors is consumed only after Wait.
+func WithErrorBuffer(n int) Option {
+	return func(p *Pool) {
+		p.errorBuffer = n
+	}
+}
+
+// WithErrorCollector makes the pool collect the errors in memory instead of sending them to Errors, so that nothing
+// blocks if nobody consumes Errors. The errors are returned by Errs, Errors is just closed by Wait. They are all kept
+// unless WithErrorRetention bounds them.
+func WithErrorCollector() Option {
+	return func(p *Pool) {
+		p.collectErrors = true
+	}
+}
+
+// WithErrorFilter makes the pool pass the errors of the functions through fn before they reach Errors, so that they can
+// be wrapped or translated in one place. When fn returns nil the error is dropped, as if the function succeeded: it
+// doesn't reach Errors nor FirstError, and doesn't stop a pool created WithFailFast. The stats still count the failure.
+func WithErrorFilter(fn func(error) error) Option {
+	return func(p *Pool) {
+		p.errorFilter = fn
+	}
+}
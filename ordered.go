@@ -0,0 +1,37 @@
+package cc
+
+// WithOrdered makes the pool deliver the errors, and the results of a PoolOf, in the order the functions were submitted
+// rather than in the order they end. The outcomes of functions that end early are kept in memory until the outcomes
+// of the functions submitted before them are delivered. Functions submitted with Submit, RunAll or RunN deliver their
+// outcome on their own and are not part of the order.
+func WithOrdered() Option {
+	return func(p *Pool) {
+		p.ordered = true
+	}
+}
+
+// deliverInOrder runs deliver, that delivers the outcome of the task with the given order, once the outcomes of all
+// the tasks submitted before it have been delivered. The deliveries are run one at a time by whichever worker
+// completes the chain.
+func (p *Pool) deliverInOrder(order uint64, deliver func()) {
+	p.mu.Lock()
+	p.outbox[order] = deliver
+	if p.delivering {
+		p.mu.Unlock()
+		return
+	}
+	p.delivering = true
+	for {
+		deliver, ok := p.outbox[p.nextOut]
+		if !ok {
+			p.delivering = false
+			p.mu.Unlock()
+			return
+		}
+		delete(p.outbox, p.nextOut)
+		p.nextOut++
+		p.mu.Unlock()
+		deliver()
+		p.mu.Lock()
+	}
+}
@@ -0,0 +1,33 @@
+package cc
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestOrderedResults(t *testing.T) {
+	p := NewOf[int](4, WithOrdered())
+	for i := range 20 {
+		p.RunResult(func() (int, error) {
+			time.Sleep(time.Duration(20-i) * 100 * time.Microsecond) // the first functions end last
+			return i, nil
+		})
+	}
+	p.Wait()
+	go func() {
+		for range p.Errors {
+		}
+	}()
+	var got []int
+	for v := range p.Results {
+		got = append(got, v)
+	}
+	want := make([]int, 20)
+	for i := range want {
+		want[i] = i
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got results %v, want them in submission order", got)
+	}
+}
@@ -0,0 +1,48 @@
+package cc
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxOriginDepth is the number of frames recorded by WithSubmitTrace, including the ones of this package
+const maxOriginDepth = 48
+
+// WithSubmitTrace makes the pool record the stack of the caller submitting each function, so that the failures of
+// anonymous closures tell where they come from: the Origin of their TaskError and of their PanicError, the latter
+// printing it too. It is meant for debugging, as it costs a runtime.Callers on every submission.
+func WithSubmitTrace() Option {
+	return func(p *Pool) {
+		p.submitTrace = true
+	}
+}
+
+// pkgPrefix is the prefix of the names of the functions of this package, see where
+var pkgPrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name() // e.g. example.com/cc.init.func1
+	slash := strings.LastIndexByte(name, '/')
+	return name[:slash+1+strings.IndexByte(name[slash+1:], '.')+1]
+}()
+
+// callers returns the stack of the current goroutine, the frames of this package being left out by where
+func callers() []uintptr {
+	pcs := make([]uintptr, maxOriginDepth)
+	return pcs[:runtime.Callers(3, pcs)]
+}
+
+// where formats the stack submitting t, if recorded, one "function\n\tfile:line" per frame like runtime/debug.Stack
+func (t *task) where() string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(t.origin)
+	for more := len(t.origin) > 0; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		if b.Len() == 0 && strings.HasPrefix(frame.Function, pkgPrefix) {
+			continue
+		}
+		b.WriteString(frame.Function + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package cc
+
+// Overflow is what a pool does with an error when Errors is full, see WithErrorOverflow
+type Overflow int
+
+const (
+	// OverflowBlock blocks the worker until the error is consumed, the default
+	OverflowBlock Overflow = iota
+	// OverflowDropOldest drops the oldest error in the buffer of Errors to make room. Without a buffer, see
+	// WithErrorBuffer, there is nothing to drop, and it drops the error like OverflowDropNewest does.
+	OverflowDropOldest
+	// OverflowDropNewest drops the error
+	OverflowDropNewest
+	// OverflowSpill keeps the error in memory, to be sent to Errors in order as soon as there is room. Wait still
+	// closes Errors only once all the errors are sent.
+	OverflowSpill
+)
+
+// WithErrorOverflow sets what the pool does with an error when Errors is full, so that a slow consumer doesn't stall
+// the workers. The dropped errors are counted in Stats. It's best combined with WithErrorBuffer.
+func WithErrorOverflow(policy Overflow) Option {
+	return func(p *Pool) {
+		p.errorOverflow = policy
+	}
+}
+
+// sendError sends err to Errors according to the overflow policy. It must be called with p.errMu held.
+func (p *Pool) sendError(err error) {
+	policy := p.errorOverflow
+	if policy == OverflowDropOldest && cap(p.Errors) == 0 {
+		policy = OverflowDropNewest // not to spin, with nothing to drop to make room
+	}
+	switch policy {
+	case OverflowDropOldest:
+		for {
+			select {
+			case p.Errors <- err:
+				return
+			default:
+			}
+			select {
+			case <-p.Errors:
+				p.dropped()
+			default:
+			}
+		}
+	case OverflowDropNewest:
+		select {
+		case p.Errors <- err:
+		default:
+			p.dropped()
+		}
+	case OverflowSpill:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if !p.spilling {
+			select {
+			case p.Errors <- err:
+				return
+			default:
+			}
+		}
+		if !p.spill.push(err) {
+			p.stats.DroppedErrors++
+		}
+		if !p.spilling {
+			p.spilling = true
+			p.spillers.Add(1)
+			p.goTracked(p.spillLoop)
+		}
+	default:
+		p.Errors <- err
+	}
+}
+
+// spillLoop sends the spilled errors to Errors, until there is none left
+func (p *Pool) spillLoop() {
+	defer p.spillers.Done()
+	p.mu.Lock()
+	for p.spill.len() > 0 {
+		err := p.spill.pop()
+		p.mu.Unlock()
+		p.Errors <- err
+		p.mu.Lock()
+	}
+	p.spilling = false
+	p.mu.Unlock()
+}
+
+func (p *Pool) dropped() {
+	p.mu.Lock()
+	p.stats.DroppedErrors++
+	p.mu.Unlock()
+}
@@ -0,0 +1,70 @@
+package cc
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// overflowing fails 5 functions one after the other with nobody consuming Errors, and returns the errors sent
+func overflowing(t *testing.T, policy Overflow) []string {
+	t.Helper()
+	var got []string
+	within(t, 5*time.Second, func() {
+		p := New(1, WithErrorBuffer(2), WithErrorOverflow(policy))
+		for i := range 5 {
+			p.Go(func() error { return errors.New(string(rune('a' + i))) })
+		}
+		<-p.idle()
+		p.Wait()
+		for err := range p.Errors {
+			got = append(got, err.Error())
+		}
+	})
+	return got
+}
+
+func TestOverflowPolicies(t *testing.T) {
+	for policy, want := range map[Overflow][]string{
+		OverflowDropNewest: {"a", "b"},
+		OverflowDropOldest: {"d", "e"},
+		OverflowSpill:      {"a", "b", "c", "d", "e"},
+	} {
+		if got := overflowing(t, policy); !slices.Equal(got, want) {
+			t.Errorf("policy %d: got errors %v, want %v", policy, got, want)
+		}
+	}
+}
+
+func TestOverflowDropOldestUnbuffered(t *testing.T) {
+	within(t, 5*time.Second, func() {
+		p := New(2, WithErrorOverflow(OverflowDropOldest))
+		for range 10 {
+			p.Go(func() error { return errors.New("failure") })
+		}
+		<-p.idle() // nobody consumes Errors meanwhile
+		p.Wait()
+		for range p.Errors {
+		}
+		if st := p.Stats(); st.Failed != 10 || st.DroppedErrors == 0 {
+			t.Errorf("stats %+v, want 10 failures and errors dropped", st)
+		}
+	})
+}
+
+func TestOverflowDropOldestBuffered(t *testing.T) {
+	p := New(1, WithErrorBuffer(2), WithErrorOverflow(OverflowDropOldest))
+	for i := range 5 {
+		p.Go(func() error { return errors.New(string(rune('a' + i))) })
+	}
+	p.Wait()
+	<-p.Done() // the pool gets there without anybody consuming Errors
+	var got []string
+	for err := range p.Errors {
+		got = append(got, err.Error())
+	}
+	if len(got) != 2 || got[1] != "e" {
+		t.Errorf("got %v, want the last 2 errors", got)
+	}
+}
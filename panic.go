@@ -0,0 +1,74 @@
+package cc
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is sent to Errors, wrapped in a TaskError, when a function run by the pool panics. It carries the
+// recovered value and the stack trace of the goroutine that panicked.
+type PanicError struct {
+	Value  any
+	Stack  []byte
+	Origin string // where the function was submitted from, see WithSubmitTrace
+}
+
+func (e *PanicError) Error() string {
+	if e.Origin != "" {
+		return fmt.Sprintf("cc: panic: %v\n\n%s\nsubmitted from:\n%s", e.Value, e.Stack, e.Origin)
+	}
+	return fmt.Sprintf("cc: panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the recovered value if it is an error, so that errors.Is and errors.As see through the panic.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// PanicPolicy is what a pool does when a function panics, see WithPanicPolicy
+type PanicPolicy int
+
+const (
+	// PanicRecover turns the panic into a PanicError, the default
+	PanicRecover PanicPolicy = iota
+	// PanicCrash records the panic as the error of the pool, see FirstError, and panics again with the same value,
+	// crashing the process
+	PanicCrash
+)
+
+// WithPanicPolicy sets what the pool does when a function panics. PanicCrash suits the panics that mean the state
+// of the process is corrupted, when dying right away is safer than going on. WithPanicHandler takes precedence.
+func WithPanicPolicy(policy PanicPolicy) Option {
+	return func(p *Pool) {
+		p.panicPolicy = policy
+	}
+}
+
+// WithCrashHandler makes the pool call fn when a function panics, with the label and the class of the function (see
+// RunClass) and the recovered value, to decide what to do with the panic instead of the policy set with
+// WithPanicPolicy. fn is called from the goroutine that panicked.
+func WithCrashHandler(fn func(label, class string, value any) PanicPolicy) Option {
+	return func(p *Pool) {
+		p.crashHandler = fn
+	}
+}
+
+// recovered handles the value recovered from a panic of t: it's handed to the panic handler if one was configured,
+// otherwise it's turned into a PanicError, unless the policy is to crash.
+func (p *Pool) recovered(t *task, r any) error {
+	if p.panicHandler != nil {
+		p.panicHandler(r)
+		return nil
+	}
+	err := &PanicError{Value: r, Stack: debug.Stack(), Origin: t.where()}
+	policy := p.panicPolicy
+	if p.crashHandler != nil {
+		policy = p.crashHandler(t.label, t.class, r)
+	}
+	if policy == PanicCrash {
+		p.failed(err)
+		panic(r)
+	}
+	return err
+}
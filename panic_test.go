@@ -0,0 +1,40 @@
+package cc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPanicError(t *testing.T) {
+	p := New(2)
+	errs := drain(p)
+	cause := errors.New("broken")
+	p.Run(func() { panic(cause) })
+	p.Run(func() {})
+	p.Wait()
+	got := errs()
+	if len(got) != 1 {
+		t.Fatalf("got errors %v, want the panic", got)
+	}
+	var pe *PanicError
+	if !errors.As(got[0], &pe) || len(pe.Stack) == 0 {
+		t.Fatalf("got %v, want a PanicError with its stack", got[0])
+	}
+	if !errors.Is(got[0], cause) {
+		t.Errorf("the PanicError doesn't unwrap to the value of the panic")
+	}
+}
+
+func TestPanicHandler(t *testing.T) {
+	recovered := make(chan any, 1)
+	p := New(1, WithPanicHandler(func(v any) { recovered <- v }))
+	errs := drain(p)
+	p.Run(func() { panic("boom") })
+	p.Wait()
+	if got := errs(); len(got) != 0 {
+		t.Errorf("got errors %v, want the panic handed to the handler", got)
+	}
+	if v := <-recovered; v != "boom" {
+		t.Errorf("the handler got %v, want boom", v)
+	}
+}
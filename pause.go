@@ -0,0 +1,31 @@
+package cc
+
+// Pause stops the workers from picking up queued functions until Resume is called. The running functions are not
+// interrupted, and new functions can still be submitted meanwhile. If the pool is cancelled while paused, the queued
+// functions are skipped as usual.
+func (p *Pool) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = true
+}
+
+// Resume lets the workers pick up the queued functions again after Pause
+func (p *Pool) Resume() {
+	p.mu.Lock()
+	if !p.paused {
+		p.mu.Unlock()
+		return
+	}
+	p.paused = false
+	p.spawnWorkers()
+	p.notEmpty.Broadcast()
+	p.mu.Unlock()
+	p.runSerial()
+}
+
+// Paused reports whether the pool is paused
+func (p *Pool) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
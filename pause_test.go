@@ -0,0 +1,32 @@
+package cc
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPause(t *testing.T) {
+	p := New(2)
+	p.Pause()
+	if !p.Paused() {
+		t.Fatal("the pool isn't paused")
+	}
+	var ran atomic.Int32
+	for range 5 {
+		if err := p.Run(func() { ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := ran.Load(); n != 0 {
+		t.Fatalf("%d functions ran while the pool was paused", n)
+	}
+	p.Resume()
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	if n := ran.Load(); n != 5 {
+		t.Errorf("ran %d functions out of 5 once resumed", n)
+	}
+}
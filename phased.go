@@ -0,0 +1,48 @@
+package cc
+
+import "context"
+
+// RunPhased runs prepare right away in its own goroutine, or in the calling one in single-threaded mode or if the pool
+// has no room for another goroutine (see WithMaxGoroutines), without taking a slot nor waiting for a worker, then queues the work it returns like Go does. It suits the functions whose
+// setup is cheap, like building a request, but whose work contends for the resource the concurrency of the pool
+// protects. If prepare fails or panics, its error is sent to Errors and there is no work to run, like when it returns
+// a nil work. It returns ErrPoolClosed if Wait or Stop was called already.
+func (p *Pool) RunPhased(prepare func() (work func() error, err error)) error {
+	t := &task{}
+	p.mu.Lock()
+	if err := p.refuse(); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	p.reserve(t)
+	p.mu.Unlock()
+	p.emitSubmitted(t.label, t.submitted)
+	if p.singleThreaded || !p.tryGo(func() { p.prepare(t, prepare) }) {
+		p.prepare(t, prepare)
+	}
+	return nil
+}
+
+// prepare runs the prepare phase of t, then queues its work
+func (p *Pool) prepare(t *task, prepare func() (func() error, error)) {
+	work, err := func() (work func() error, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = p.recovered(t, r)
+			}
+		}()
+		return prepare()
+	}()
+	if err != nil {
+		p.finish(t, &TaskError{TaskInfo: TaskInfo{Pool: p.name, Submitted: t.submitted, Seq: t.id, Err: err, Origin: t.where()}})
+		return
+	}
+	if work == nil {
+		p.finish(t, nil)
+		return
+	}
+	t.fn = func(context.Context) error {
+		return work()
+	}
+	p.requeue(t)
+}
@@ -0,0 +1,24 @@
+package cc
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunPhasedNoRoom(t *testing.T) {
+	p := New(1, WithMaxGoroutines(1))
+	release := make(chan struct{})
+	p.Run(func() { <-release }) // its worker takes the only room of the pool
+	var prepared atomic.Bool
+	p.RunPhased(func() (func() error, error) {
+		prepared.Store(true)
+		return func() error { return nil }, nil
+	})
+	if !prepared.Load() {
+		t.Error("RunPhased returned before preparing the function in a pool without room for another goroutine")
+	}
+	close(release)
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,160 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Pipeline chains stages connected by typed channels, each stage with its own concurrency limit.
+// The errors of all the stages are merged into Errors, that must be consumed like the one of a Pool.
+//
+// Example:
+//
+//	pl := cc.NewPipeline(ctx)
+//	pages := cc.Then(cc.From(pl, urls), 8, fetch)
+//	sizes := cc.Then(pages, 2, measure)
+//	pl.Wait()
+//
+//	go func() {
+//		for size := range sizes.Out() {
+//
+//		}
+//	}()
+//	for err := range pl.Errors {
+//
+//	}
+type Pipeline struct {
+	Errors chan error
+
+	ctx    context.Context
+	stages sync.WaitGroup
+}
+
+// NewPipeline returns a new pipeline bound to ctx: when ctx is cancelled all the stages stop
+func NewPipeline(ctx context.Context) *Pipeline {
+	return &Pipeline{
+		Errors: make(chan error),
+		ctx:    ctx,
+	}
+}
+
+// Wait doesn't block, but ensures that Errors is closed when all the stages end. It must be called once all the stages
+// have been added.
+func (pl *Pipeline) Wait() {
+	goTracked(func() {
+		pl.stages.Wait()
+		close(pl.Errors)
+	})
+}
+
+// Stage is a step of a pipeline, producing values of type T
+type Stage[T any] struct {
+	pl  *Pipeline
+	out <-chan T
+}
+
+// Out returns the channel where the stage sends its values, closed when the stage ends. It must be consumed,
+// unless the stage is followed by another one.
+func (s *Stage[T]) Out() <-chan T {
+	return s.out
+}
+
+// From returns the first stage of pl, producing the values received from in
+func From[T any](pl *Pipeline, in <-chan T) *Stage[T] {
+	return &Stage[T]{pl: pl, out: in}
+}
+
+// Then returns a new stage after s, calling fn on each value produced by s with at most concurrency calls at the same time.
+// Values are produced in completion order. The errors returned by fn are sent to the Errors of the pipeline,
+// and the corresponding values are dropped. If the stage refuses a value, the refusal is sent to Errors as well and the
+// stage stops receiving from s. At most concurrency values wait for a call, so that when the stage is slower than s the
+// workers of s block sending their values, instead of piling them up in memory.
+func Then[T, R any](s *Stage[T], concurrency int, fn func(ctx context.Context, v T) (R, error)) *Stage[R] {
+	pl := s.pl
+	out := make(chan R)
+	p := newPool(pl.ctx, concurrency, max(concurrency, 1), nil)
+	p.closers = append(p.closers, func() { close(out) })
+
+	p.goTracked(func() {
+		defer p.Wait()
+		for {
+			select {
+			case v, ok := <-s.out:
+				if !ok {
+					return
+				}
+				err := p.push(&task{fn: func(ctx context.Context) error {
+					r, err := fn(ctx, v)
+					if err != nil {
+						return err
+					}
+					select {
+					case out <- r:
+					case <-ctx.Done():
+					}
+					return nil
+				}})
+				if err != nil {
+					pl.Errors <- err // before the stage ends, so Errors is still open
+					return
+				}
+			case <-pl.ctx.Done():
+				return
+			}
+		}
+	})
+
+	pl.stages.Add(1)
+	p.goTracked(func() {
+		defer pl.stages.Done()
+		for err := range p.Errors {
+			pl.Errors <- err
+		}
+	})
+	return &Stage[R]{pl: pl, out: out}
+}
+
+// Transform calls fn on each value received from in, with at most concurrency calls at the same time, and sends the
+// results to out in completion order. It returns once in is closed and all the results are sent, or once ctx is done,
+// closing out in both cases: the consumer of out only has to cancel ctx to stop Transform, which then stops receiving
+// from in. The values whose fn fails are dropped, and the errors are returned joined with errors.Join, along with the
+// error of ctx if it was cancelled. If the pool refuses a value, Transform stops receiving from in and returns the
+// reason among the errors.
+func Transform[T, R any](ctx context.Context, in <-chan T, out chan<- R, concurrency int, fn func(T) (R, error)) error {
+	defer close(out)
+	p := NewWithContext(ctx, concurrency, WithQueueSize(concurrency), WithErrorCollector())
+	var errs []error
+loop:
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				break loop
+			}
+			if err := p.push(&task{fn: func(ctx context.Context) error {
+				r, err := fn(v)
+				if err != nil {
+					return err
+				}
+				select {
+				case out <- r:
+				case <-ctx.Done():
+				}
+				return nil
+			}}); err != nil {
+				errs = append(errs, err)
+				break loop
+			}
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	p.Wait()
+	<-p.Done()
+	errs = append(errs, p.Errs()...)
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
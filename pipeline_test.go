@@ -0,0 +1,88 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPipeline(t *testing.T) {
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := range 10 {
+			in <- i
+		}
+	}()
+	odd := errors.New("odd")
+	pl := NewPipeline(context.Background())
+	evens := Then(From(pl, in), 3, func(_ context.Context, v int) (int, error) {
+		if v%2 == 1 {
+			return 0, odd
+		}
+		return v, nil
+	})
+	squares := Then(evens, 2, func(_ context.Context, v int) (int, error) { return v * v, nil })
+	pl.Wait()
+	var errs []error
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for err := range pl.Errors {
+			errs = append(errs, err)
+		}
+	}()
+	sum := 0
+	for v := range squares.Out() {
+		sum += v
+	}
+	<-drained
+	if sum != 0+4+16+36+64 {
+		t.Errorf("got a sum of %d, want the squares of the even values", sum)
+	}
+	if len(errs) != 5 || !errors.Is(errs[0], odd) {
+		t.Errorf("got errors %v, want one for each odd value", errs)
+	}
+}
+
+func TestThenBounded(t *testing.T) {
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := range 200 {
+			in <- i
+		}
+	}()
+	var produced, consumed atomic.Int64
+	var ahead atomic.Int64 // the most values produced before the slow stage got to them
+	pl := NewPipeline(context.Background())
+	fast := Then(From(pl, in), 4, func(_ context.Context, v int) (int, error) {
+		produced.Add(1)
+		return v, nil
+	})
+	slow := Then(fast, 2, func(_ context.Context, v int) (int, error) {
+		n := produced.Load() - consumed.Add(1)
+		for a := ahead.Load(); n > a && !ahead.CompareAndSwap(a, n); a = ahead.Load() {
+		}
+		time.Sleep(100 * time.Microsecond)
+		return v, nil
+	})
+	pl.Wait()
+	go func() {
+		for range pl.Errors {
+		}
+	}()
+	n := 0
+	for range slow.Out() {
+		n++
+	}
+	if n != 200 {
+		t.Fatalf("got %d values, want 200", n)
+	}
+	// the values running and queued in the slow stage, plus the ones held by the fast stage and its feeder
+	if bound := int64(2 + 2 + 4 + 1); ahead.Load() > bound {
+		t.Errorf("the fast stage got %d values ahead of the slow one, want at most %d", ahead.Load(), bound)
+	}
+}
@@ -0,0 +1,27 @@
+package cc
+
+import (
+	"context"
+	"runtime/pprof"
+	"time"
+)
+
+// WithProfilerLabels makes the pool tag the goroutine running each function with the pprof labels "cc_pool", the
+// name of the pool, and "cc_task", the label of the function, so that CPU profiles and goroutine dumps attribute the
+// time to the functions. The labels are also in the context given to the function, see pprof.Label.
+func WithProfilerLabels() Option {
+	return func(p *Pool) {
+		p.profilerLabels = true
+	}
+}
+
+// profiled runs t with the pprof labels of the pool, if enabled
+func (p *Pool) profiled(ctx context.Context, t *task, start time.Time) (err error) {
+	if !p.profilerLabels {
+		return p.around(ctx, t, start)
+	}
+	pprof.Do(ctx, pprof.Labels("cc_pool", p.name, "cc_task", t.label), func(ctx context.Context) {
+		err = p.around(ctx, t, start)
+	})
+	return err
+}
@@ -0,0 +1,24 @@
+package cc
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// NewCPU returns a pool suited to CPU-bound functions: it runs as many functions at the same time as the process can
+// run goroutines in parallel, see runtime.GOMAXPROCS, and its queue holds as many functions, as queueing more wouldn't
+// make them end sooner. The options are applied after the defaults, so that they can override them.
+func NewCPU(opts ...Option) *Pool {
+	n := runtime.GOMAXPROCS(0)
+	return newPool(context.Background(), n, n, opts)
+}
+
+// NewIO returns a pool suited to functions waiting on I/O, running at most limit of them at the same time: its queue
+// holds 16 functions per worker, so that the producers can get ahead of slow backends, and its idle workers exit after
+// a minute, so that a pool serving occasional bursts doesn't keep them forever. The options are applied after the
+// defaults, so that they can override them.
+func NewIO(limit int, opts ...Option) *Pool {
+	defaults := []Option{WithIdleTimeout(time.Minute)}
+	return newPool(context.Background(), limit, 16*max(limit, 1), append(defaults, opts...))
+}
@@ -0,0 +1,54 @@
+package cc
+
+import "context"
+
+// WithPrespawn makes the pool start n workers, up to its concurrency, right away instead of on demand, so that the
+// first functions don't wait for a worker to start and run its init hook. The workers still exit with WithIdleTimeout.
+func WithPrespawn(n int) Option {
+	return func(p *Pool) {
+		p.prespawn = n
+	}
+}
+
+// Prewarm starts all the workers of the pool, up to its concurrency, and blocks until they ran their init hook, see
+// WithWorkerInit, so that the pool is ready ahead of the traffic. It returns the error of ctx if it's done first,
+// and ErrPoolClosed if the pool is closed.
+func (p *Pool) Prewarm(ctx context.Context) error {
+	p.mu.Lock()
+	if p.state != stateOpen {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+	p.spawnIdle(p.concurrency)
+	if p.initialized >= p.concurrency {
+		p.mu.Unlock()
+		return nil
+	}
+	warm := make(chan struct{})
+	p.warmWaiters = append(p.warmWaiters, warm)
+	p.mu.Unlock()
+
+	select {
+	case <-warm:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// spawnIdle starts workers until there are n, up to the concurrency. It must be called with p.mu held.
+func (p *Pool) spawnIdle(n int) {
+	for p.nworkers < min(n, p.concurrency) && p.spawn() {
+	}
+}
+
+// notifyWarm wakes Prewarm up once all the workers are initialized. It must be called with p.mu held.
+func (p *Pool) notifyWarm() {
+	if p.initialized < p.concurrency {
+		return
+	}
+	for _, c := range p.warmWaiters {
+		close(c)
+	}
+	p.warmWaiters = nil
+}
@@ -0,0 +1,54 @@
+package cc
+
+// pressureWaiter is a channel closed once the pressure of the pool goes below threshold, see WhenBelow
+type pressureWaiter struct {
+	threshold float64
+	c         chan struct{}
+}
+
+// Pressure returns how saturated the pool is, from 0 when idle to 1: the slots taken by the running functions plus the
+// queued functions, out of the concurrency plus the size of the queue of a bounded pool. An unbounded pool reaches 1
+// as soon as its workers are all busy. Producers can use it to pace themselves instead of blocking in Run.
+func (p *Pool) Pressure() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pressure()
+}
+
+// WhenBelow returns a channel closed as soon as the Pressure of the pool is below threshold, right away if it is
+// already, e.g. for a producer to wait before submitting its next batch.
+func (p *Pool) WhenBelow(threshold float64) <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c := make(chan struct{})
+	if p.pressure() < threshold {
+		close(c)
+		return c
+	}
+	p.pressureWaiters = append(p.pressureWaiters, pressureWaiter{threshold, c})
+	return c
+}
+
+// pressure returns the Pressure of the pool. It must be called with p.mu held.
+func (p *Pool) pressure() float64 {
+	capacity := p.concurrency + max(p.queueSize, 0)
+	return min(1, float64(p.used+p.queued())/float64(capacity))
+}
+
+// relieved closes the channels of WhenBelow whose threshold the pressure went below. It must be called with p.mu held.
+func (p *Pool) relieved() {
+	if len(p.pressureWaiters) == 0 {
+		return
+	}
+	pressure := p.pressure()
+	waiters := p.pressureWaiters[:0]
+	for _, w := range p.pressureWaiters {
+		if pressure < w.threshold {
+			close(w.c)
+		} else {
+			waiters = append(waiters, w)
+		}
+	}
+	clear(p.pressureWaiters[len(waiters):])
+	p.pressureWaiters = waiters
+}
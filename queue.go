@@ -0,0 +1,104 @@
+package cc
+
+import (
+	"container/heap"
+	"math/rand/v2"
+)
+
+// taskQueue holds the tasks waiting for a worker, highest priority first and then in the order of its schedule
+type taskQueue struct {
+	tasks    []*task
+	seq      uint64
+	schedule Schedule
+	mem      int64 // the memory held by the tasks, see WithQueueMemory
+
+	fair   bool              // whether the submitters take turns, see WithFairness
+	round  uint64            // the round of the last task popped
+	rounds map[string]uint64 // the next round of each submitter ahead of the current one
+}
+
+func (q *taskQueue) push(t *task) {
+	q.seq++
+	t.seq = q.seq
+	if q.schedule == ScheduleRandom {
+		t.seq = rand.Uint64()
+	}
+	if q.fair {
+		t.round = max(q.rounds[t.submitter], q.round)
+		if q.rounds == nil {
+			q.rounds = map[string]uint64{}
+		}
+		q.rounds[t.submitter] = t.round + 1
+	}
+	heap.Push(q, t)
+}
+
+func (q *taskQueue) pop() *task {
+	t := heap.Pop(q).(*task)
+	if q.fair {
+		q.round = t.round
+		for s, r := range q.rounds {
+			if r <= q.round {
+				delete(q.rounds, s) // the submitter has nothing queued ahead of the current round
+			}
+		}
+	}
+	return t
+}
+
+// peek returns the next task without removing it
+func (q *taskQueue) peek() *task {
+	return q.tasks[0]
+}
+
+// remove removes t from the queue, and returns false if it wasn't queued
+func (q *taskQueue) remove(t *task) bool {
+	if t.index < 0 || t.index >= len(q.tasks) || q.tasks[t.index] != t {
+		return false
+	}
+	heap.Remove(q, t.index)
+	return true
+}
+
+// Len, Less, Swap, Push and Pop implement heap.Interface, use push and pop instead.
+
+func (q *taskQueue) Len() int {
+	return len(q.tasks)
+}
+
+func (q *taskQueue) Less(i, j int) bool {
+	a, b := q.tasks[i], q.tasks[j]
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	if a.round != b.round {
+		return a.round < b.round
+	}
+	if q.schedule == ScheduleLIFO {
+		return a.seq > b.seq
+	}
+	return a.seq < b.seq
+}
+
+func (q *taskQueue) Swap(i, j int) {
+	q.tasks[i], q.tasks[j] = q.tasks[j], q.tasks[i]
+	q.tasks[i].index = i
+	q.tasks[j].index = j
+}
+
+func (q *taskQueue) Push(x any) {
+	t := x.(*task)
+	t.index = len(q.tasks)
+	q.tasks = append(q.tasks, t)
+	q.mem += t.size
+}
+
+func (q *taskQueue) Pop() any {
+	n := len(q.tasks) - 1
+	t := q.tasks[n]
+	t.index = -1
+	q.mem -= t.size
+	q.tasks[n] = nil
+	q.tasks = q.tasks[:n]
+	return t
+}
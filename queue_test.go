@@ -0,0 +1,23 @@
+package cc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRunPriority(t *testing.T) {
+	p := New(1)
+	errs := drain(p)
+	release := make(chan struct{})
+	p.Run(func() { <-release }) // holding the only worker while the others are queued
+	var order []int
+	for i, priority := range []int{0, 5, 1, 5, 0} {
+		p.RunPriority(priority, func() { order = append(order, i) })
+	}
+	close(release)
+	p.Wait()
+	errs()
+	if want := []int{1, 3, 2, 0, 4}; !slices.Equal(order, want) {
+		t.Errorf("ran the functions in the order %v, want %v", order, want)
+	}
+}
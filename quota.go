@@ -0,0 +1,35 @@
+package cc
+
+import "errors"
+
+// ErrQuotaExceeded is returned when submitting a function to a pool that used up its quota, see Pool.Quota
+var ErrQuotaExceeded = errors.New("cc: quota exceeded")
+
+// Quota caps at n the number of functions the pool accepts over its whole life, counting the ones submitted already,
+// e.g. to stay within the daily quota of an API. The submissions beyond it fail with ErrQuotaExceeded, and the retries
+// of a function don't count. A negative n removes the cap. Stats tells how many functions can still be submitted.
+func (p *Pool) Quota(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.quota = n
+}
+
+// refuse returns why a new task can't be submitted, ErrPoolClosed or ErrQuotaExceeded, or nil if it can.
+// It must be called with p.mu held.
+func (p *Pool) refuse() error {
+	if p.state != stateOpen {
+		return ErrPoolClosed
+	}
+	if p.quota >= 0 && p.submissions >= uint64(p.quota) {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// quotaLeft returns how many functions can still be submitted, or -1 without a quota. It must be called with p.mu held.
+func (p *Pool) quotaLeft() int {
+	if p.quota < 0 {
+		return -1
+	}
+	return max(0, p.quota-int(p.submissions))
+}
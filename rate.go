@@ -0,0 +1,102 @@
+package cc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit makes the pool start at most perSecond functions per second, with bursts of up to burst functions.
+// The limit applies when a worker picks a function up, in addition to the concurrency limit. A perSecond of 0 or less
+// means no limit.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(p *Pool) {
+		p.rateLimiter = newRateLimiter(perSecond, burst)
+	}
+}
+
+// WithSubmitRate makes the submissions of functions one at a time, with Run and its variants, block so that at most
+// perSecond functions are accepted per second, with bursts of up to burst functions. Unlike WithRateLimit it applies
+// backpressure to the producers, keeping the queue short when they are much faster than the workers. TryRun returns
+// false instead of blocking. A perSecond of 0 or less means no limit.
+func WithSubmitRate(perSecond float64, burst int) Option {
+	return func(p *Pool) {
+		p.submitLimiter = newRateLimiter(perSecond, burst)
+	}
+}
+
+// rateLimiter is a token bucket. The tokens go negative when they are reserved ahead of time by waiting callers.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	clock  Clock
+}
+
+// newRateLimiter returns a full bucket, or nil for no limit if perSecond isn't positive. Its clock is set once the
+// options of the pool are applied, see setClock.
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	if !(perSecond > 0) {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// setClock makes l tell the time with c, from now on
+func (l *rateLimiter) setClock(c Clock) {
+	l.clock = c
+	l.last = c.Now()
+}
+
+// wait blocks until a token is available. It returns an error, and gives the token back, if ctx is done first.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.clock.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	ready := make(chan struct{})
+	timer := l.clock.AfterFunc(delay, func() { close(ready) })
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// allow takes a token if one is available right away, and reports whether it did
+func (l *rateLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
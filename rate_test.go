@@ -0,0 +1,68 @@
+package cc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	p := New(4, WithRateLimit(100, 2))
+	start := time.Now()
+	for range 7 {
+		p.Run(func() {})
+	}
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	// the burst starts 2 functions right away, the other 5 wait for 10ms each
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("started 7 functions in %s, want at least 50ms at 100 per second", elapsed)
+	}
+}
+
+func TestSubmitRate(t *testing.T) {
+	p := New(4, WithSubmitRate(100, 2))
+	defer p.WaitErr()
+	start := time.Now()
+	for range 4 {
+		p.Run(func() {})
+	}
+	// the burst accepts 2 functions right away, the other 2 wait for 10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("accepted 4 functions in %s, want at least 20ms at 100 per second", elapsed)
+	}
+	if p.TryRun(func() {}) {
+		t.Error("TryRun accepted a function beyond the submission rate")
+	}
+}
+
+func TestRateLimitUnlimited(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		p := New(4, WithRateLimit(rate, 1))
+		within(t, 5*time.Second, func() {
+			for range 100 {
+				p.Run(func() {})
+			}
+			if err := p.WaitErr(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestSubmitRateUnlimited(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		p := New(1000, WithSubmitRate(rate, 1)) // TryRun having room for them all
+		within(t, 5*time.Second, func() {
+			for range 100 {
+				p.Run(func() {})
+				if !p.TryRun(func() {}) {
+					t.Fatalf("TryRun refused a function with a rate of %v", rate)
+				}
+			}
+			if err := p.WaitErr(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package cc
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Codec serializes the payloads of the jobs, see Register
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec encodes payloads with encoding/json
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// GobCodec encodes payloads with encoding/gob
+type GobCodec struct{}
+
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (GobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// registered is a handler given to Register
+type registered struct {
+	codec  Codec
+	handle func(ctx context.Context, data []byte) error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]registered{}
+)
+
+// Register registers handler for the jobs named name, whose payloads are encoded with codec, JSON if nil.
+// The processes sharing a Queue register the same handlers, and build jobs with NewJob and run them with Dispatch.
+// Registering the same name again replaces the handler.
+func Register[T any](name string, codec Codec, handler func(ctx context.Context, payload T) error) {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = registered{codec: codec, handle: func(ctx context.Context, data []byte) error {
+		var payload T
+		if err := codec.Unmarshal(data, &payload); err != nil {
+			return fmt.Errorf("cc: decoding payload of %s: %w", name, err)
+		}
+		return handler(ctx, payload)
+	}}
+}
+
+// NewJob returns a job for the handler registered as name, with payload encoded by the codec of the handler
+func NewJob(name string, payload any) (Job, error) {
+	r, err := lookup(name)
+	if err != nil {
+		return Job{}, err
+	}
+	data, err := r.codec.Marshal(payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("cc: encoding payload of %s: %w", name, err)
+	}
+	return Job{Name: name, Payload: data}, nil
+}
+
+// Dispatch runs job with the handler registered for its name. It's the handler to give to Pool.Serve, e.g.
+// p.Serve(ctx, q, cc.Dispatch).
+func Dispatch(ctx context.Context, job Job) error {
+	r, err := lookup(job.Name)
+	if err != nil {
+		return err
+	}
+	return r.handle(ctx, job.Payload)
+}
+
+func lookup(name string) (registered, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[name]
+	if !ok {
+		return registered{}, fmt.Errorf("cc: no handler registered for %s", name)
+	}
+	return r, nil
+}
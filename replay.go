@@ -0,0 +1,63 @@
+package cc
+
+// Recording is the order in which a pool started its functions, to replay it with WithReplay. The functions are
+// identified by their submission sequence number, see TaskInfo, and appear once per attempt. Seqs must be read once
+// the pool is done, e.g. to save it as JSON.
+type Recording struct {
+	Seqs []uint64
+}
+
+// WithRecording makes the pool record in r the order in which it starts its functions
+func WithRecording(r *Recording) Option {
+	return func(p *Pool) {
+		p.recording = r
+	}
+}
+
+// WithReplay makes the pool start its functions in the order recorded in r by a previous run, one at a time, to
+// reproduce the bugs that depend on the order of the functions. The functions must be submitted in the same order as
+// in the recorded run: the pool waits for the next function of the recording to be submitted, running nothing else
+// meanwhile, unless nothing else can be submitted anymore. Then, or once the recording is over, the pool runs the rest
+// of the functions in the usual order.
+func WithReplay(r *Recording) Option {
+	return func(p *Pool) {
+		p.replay = r.Seqs
+	}
+}
+
+// replayed returns the next task of the replay if it's queued, or the next task of the queue if there is no replay.
+// It must be called with p.mu held.
+func (p *Pool) replayed() *task {
+	if p.queue.Len() == 0 {
+		return nil
+	}
+	if len(p.replay) == 0 {
+		return p.queue.peek()
+	}
+	for _, t := range p.queue.tasks {
+		if t.id == p.replay[0] {
+			return t
+		}
+	}
+	if p.state != stateOpen && p.used == 0 && p.pending == p.queue.Len() {
+		// the next task of the replay will never come, the run diverged from the recorded one
+		p.replay = nil
+		return p.queue.peek()
+	}
+	return nil
+}
+
+// take removes t, the next task returned by replayed, from the queue and records it. It must be called with p.mu held.
+func (p *Pool) take(t *task) {
+	if len(p.replay) > 0 {
+		p.replay = p.replay[1:]
+	}
+	if t == p.queue.peek() {
+		p.queue.pop()
+	} else {
+		p.queue.remove(t)
+	}
+	if p.recording != nil {
+		p.recording.Seqs = append(p.recording.Seqs, t.id)
+	}
+}
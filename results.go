@@ -0,0 +1,83 @@
+package cc
+
+import (
+	"context"
+	"iter"
+)
+
+// PoolOf is a Pool whose functions return a value of type T along with an error.
+// Values are sent on Results and errors on Errors: both channels must be consumed, and both are closed by Wait
+// once all the functions end.
+//
+// Example:
+//
+//	p := cc.NewOf[int](4)
+//	p.RunResult(func() (int, error) {
+//		return compute()
+//	})
+//	p.Wait()
+//
+//	results, errs := p.Results, p.Errors
+//	for results != nil || errs != nil {
+//		select {
+//		case v, ok := <-results:
+//			if !ok {
+//				results = nil
+//			}
+//		case err, ok := <-errs:
+//			if !ok {
+//				errs = nil
+//			}
+//		}
+//	}
+type PoolOf[T any] struct {
+	*Pool
+	Results chan T
+}
+
+// NewOf returns a new PoolOf where a limited number (concurrency) of goroutine can work at the same time
+func NewOf[T any](concurrency int, opts ...Option) *PoolOf[T] {
+	p := &PoolOf[T]{
+		Pool:    New(concurrency, opts...),
+		Results: make(chan T),
+	}
+	p.closers = append(p.closers, func() { close(p.Results) })
+	return p
+}
+
+// RunResult runs fn like Run does. If fn returns a nil error its value is sent to Results, otherwise the error is sent to Errors.
+func (p *PoolOf[T]) RunResult(fn func() (T, error)) error {
+	return p.push(&task{produce: func(context.Context) (func(), error) {
+		v, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		return func() { p.Results <- v }, nil
+	}})
+}
+
+// All returns an iterator over the values and the errors of the functions, in completion order, yielding the zero
+// value along with each error. The iteration ends once Wait was called and all the functions ended. If the loop
+// breaks early the rest of the values and errors must still be consumed.
+func (p *PoolOf[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		results, errs := p.Results, p.Errors
+		for results != nil || errs != nil {
+			select {
+			case v, ok := <-results:
+				if !ok {
+					results = nil
+				} else if !yield(v, nil) {
+					return
+				}
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+				} else if !yield(zero, err) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package cc
+
+// A pool keeps in memory, for as long as it lives, only what its options ask for, so that a pool serving a daemon
+// for weeks stays within bounds:
+//   - the stats are counters, and the wait percentiles and the recent errors of Debug are kept in fixed-size rings,
+//   - the tasks, keys, tags and flights are dropped once done, and the circuits once their label succeeds or stops
+//     failing for a cooldown,
+//   - the errors collected by WithErrorCollector, and the ones spilled by OverflowSpill, pile up until consumed,
+//     unless WithErrorRetention bounds them,
+//   - the results memoized by WithMemoize are kept for their ttl, and at most as many as WithMemoRetention allows,
+//   - a Recording of WithRecording keeps one entry per attempt: it's meant for reproducing a run, not for a daemon.
+
+// WithErrorRetention keeps at most n of the errors a pool holds in memory: the ones collected by WithErrorCollector
+// and the ones spilled by OverflowSpill waiting for room in Errors. Beyond it the oldest ones are dropped, and counted
+// in Stats as dropped. A pool with a collector running for long should have one, Errs returning the last n errors.
+func WithErrorRetention(n int) Option {
+	return func(p *Pool) {
+		p.errs.limit = n
+		p.spill.limit = n
+	}
+}
+
+// WithMemoRetention keeps at most n results memoized by WithMemoize, the oldest ones being forgotten first, so that a
+// pool running with many distinct keys doesn't grow when their ttl is long.
+func WithMemoRetention(n int) Option {
+	return func(p *Pool) {
+		p.memoOrder.limit = n
+	}
+}
+
+// memo is a result memoized for key by WithMemoize
+type memo struct {
+	key   string
+	t     *Task[any]
+	timer Timer // expiring it after the ttl
+}
+
+// memoize keeps t as the result of key for the ttl, forgetting the oldest result beyond the retention. It must be
+// called with p.mu held.
+func (p *Pool) memoize(key string, t *Task[any]) {
+	m := &memo{key: key, t: t}
+	m.timer = p.clock.AfterFunc(p.memoTTL, func() { p.expire(m) })
+	p.memos[key] = m
+	if p.memoOrder.limit <= 0 {
+		return
+	}
+	if p.memoOrder.full() {
+		if oldest := p.memoOrder.peek(); p.memos[oldest.key] == oldest {
+			p.forget(oldest)
+		}
+	}
+	p.memoOrder.push(m)
+}
+
+// forget drops the result kept by m, stopping its timer not to hold it until the ttl. It must be called with p.mu held.
+func (p *Pool) forget(m *memo) {
+	m.timer.Stop()
+	delete(p.memos, m.key)
+}
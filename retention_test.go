@@ -0,0 +1,49 @@
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestErrorRetention(t *testing.T) {
+	p := New(4, WithErrorCollector(), WithErrorRetention(5))
+	for i := range 50 {
+		p.Go(func() error { return fmt.Errorf("failure %d", i) })
+	}
+	p.WaitBatch()
+	errs := p.Errs()
+	if len(errs) != 5 {
+		t.Fatalf("kept %d errors, want 5", len(errs))
+	}
+	if stats := p.Stats(); stats.DroppedErrors != 45 {
+		t.Errorf("dropped %d errors, want 45", stats.DroppedErrors)
+	}
+	p.WaitErr()
+}
+
+func TestMemoRetention(t *testing.T) {
+	p := New(4, WithMemoize(time.Hour), WithMemoRetention(5))
+	defer p.WaitErr()
+	runs := 0
+	for i := range 50 {
+		key := strconv.Itoa(i)
+		if _, err := p.RunOnce(key, func() (any, error) { runs++; return key, nil }).Result(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.mu.Lock()
+	memos := len(p.memos)
+	p.mu.Unlock()
+	if memos != 5 {
+		t.Errorf("memoized %d results, want 5", memos)
+	}
+	for _, key := range []string{"49", "0"} {
+		p.RunOnce(key, func() (any, error) { runs++; return nil, errors.New("ran again") }).Result()
+	}
+	if runs != 51 {
+		t.Errorf("ran %d functions, want 51: the last result memoized, the first one forgotten", runs)
+	}
+}
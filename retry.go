@@ -0,0 +1,49 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff returns how long to wait before the given retry attempt, 1 being the first retry.
+type Backoff func(attempt int) time.Duration
+
+// ExponentialBackoff returns a Backoff starting from base and doubling at each attempt, up to max.
+// A random jitter of up to half the delay is subtracted, so that retries of many functions don't happen in lockstep.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		if d <= 0 {
+			return 0
+		}
+		return d - rand.N(d/2+1)
+	}
+}
+
+// retry queues t again after the backoff delay, if it has attempts left. It returns false if t must not be retried.
+func (p *Pool) retry(ctx context.Context, t *task, err error) bool {
+	var perr *PanicError
+	if t.attempt+1 >= p.attempts || ctx.Err() != nil || errors.As(err, &perr) || Classify(err) == ClassPermanent {
+		return false
+	}
+	t.attempt++
+	t.err = err
+	var delay time.Duration
+	if p.backoff != nil {
+		delay = p.backoff(t.attempt)
+	}
+	if delay <= 0 && p.serial {
+		p.requeue(t) // run by the goroutine running t, once t returns
+		return true
+	}
+	p.clock.AfterFunc(delay, func() { p.requeue(t) })
+	return true
+}
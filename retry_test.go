@@ -0,0 +1,55 @@
+package cc
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	p := New(2, WithRetry(3, ExponentialBackoff(time.Millisecond, 5*time.Millisecond)))
+	errs := drain(p)
+	failure := errors.New("failure")
+	var flaky, broken atomic.Int32
+	p.Go(func() error {
+		if flaky.Add(1) < 3 {
+			return failure
+		}
+		return nil
+	})
+	p.Go(func() error {
+		broken.Add(1)
+		return failure
+	})
+	p.Wait()
+	got := errs()
+	if len(got) != 1 || !errors.Is(got[0], failure) {
+		t.Errorf("got errors %v, want the last failure of the broken function only", got)
+	}
+	if flaky.Load() != 3 || broken.Load() != 3 {
+		t.Errorf("ran the functions %d and %d times, want 3 attempts each", flaky.Load(), broken.Load())
+	}
+}
+
+func TestRetryPanic(t *testing.T) {
+	p := New(1, WithRetry(3, nil))
+	errs := drain(p)
+	var runs atomic.Int32
+	p.Run(func() { runs.Add(1); panic("boom") })
+	p.Wait()
+	errs()
+	if runs.Load() != 1 {
+		t.Errorf("ran a panicking function %d times, want no retry", runs.Load())
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 30*time.Millisecond)
+	for attempt, high := range map[int]time.Duration{1: 10, 2: 20, 3: 30, 10: 30} {
+		high *= time.Millisecond
+		if d := backoff(attempt); d > high || d < high/2 {
+			t.Errorf("waiting %s before the attempt %d, want between %s and %s", d, attempt, high/2, high)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cc
+
+import "errors"
+
+// ErrorClass tells whether an error is worth retrying, see Retryable and Permanent
+type ErrorClass int
+
+const (
+	ClassUnmarked  ErrorClass = iota // neither Retryable nor Permanent: retried with WithRetry
+	ClassRetryable                   // marked with Retryable
+	ClassPermanent                   // marked with Permanent: never retried
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ClassRetryable:
+		return "retryable"
+	case ClassPermanent:
+		return "permanent"
+	}
+	return "unmarked"
+}
+
+// retryableError is an error marked with Retryable
+type retryableError struct{ err error }
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// permanentError is an error marked with Permanent
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Retryable marks err as worth retrying, e.g. a timeout or a 503, returning nil if err is nil. The pool retries it
+// with WithRetry like the unmarked errors, but Classify and ErrorSummary tell it apart.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err}
+}
+
+// Permanent marks err as not worth retrying, e.g. a validation error or a 404, returning nil if err is nil: the pool
+// sends it to Errors at once, without using the attempts left with WithRetry.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// Classify returns the class of err: the one of the outermost Retryable or Permanent in its chain, if any
+func Classify(err error) ErrorClass {
+	for err != nil {
+		switch e := err.(type) {
+		case *retryableError:
+			return ClassRetryable
+		case *permanentError:
+			return ClassPermanent
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				if c := Classify(err); c != ClassUnmarked {
+					return c
+				}
+			}
+			return ClassUnmarked
+		}
+		err = errors.Unwrap(err)
+	}
+	return ClassUnmarked
+}
+
+// unclassified returns err without its Retryable or Permanent mark, if it has one at its top
+func unclassified(err error) error {
+	switch e := err.(type) {
+	case *retryableError:
+		return e.err
+	case *permanentError:
+		return e.err
+	}
+	return err
+}
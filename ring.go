@@ -0,0 +1,73 @@
+package cc
+
+// ring is a FIFO of values in a ring buffer, growing as needed up to limit values if limit is positive: beyond it,
+// pushing a value drops the oldest one. Unlike a slice resliced from its head, it doesn't keep reallocating nor
+// retaining the memory of the values gone, so that a pool running for weeks stays within bounds.
+type ring[T any] struct {
+	buf   []T
+	head  int // the index of the oldest value
+	n     int // the number of values
+	limit int
+}
+
+// push adds v, returning false if the oldest value was dropped to make room for it
+func (r *ring[T]) push(v T) bool {
+	if r.full() {
+		r.buf[r.head] = v
+		r.head = (r.head + 1) % len(r.buf)
+		return false
+	}
+	if r.n == len(r.buf) {
+		size := max(2*len(r.buf), 8)
+		if r.limit > 0 {
+			size = min(size, r.limit)
+		}
+		buf := make([]T, size)
+		r.copyTo(buf)
+		r.buf, r.head = buf, 0
+	}
+	r.buf[(r.head+r.n)%len(r.buf)] = v
+	r.n++
+	return true
+}
+
+// pop removes and returns the oldest value. The ring must not be empty.
+func (r *ring[T]) pop() T {
+	v := r.buf[r.head]
+	var zero T
+	r.buf[r.head] = zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.n--
+	return v
+}
+
+// peek returns the oldest value. The ring must not be empty.
+func (r *ring[T]) peek() T {
+	return r.buf[r.head]
+}
+
+// full reports whether pushing a value drops the oldest one
+func (r *ring[T]) full() bool {
+	return r.limit > 0 && r.n == r.limit
+}
+
+// len returns the number of values
+func (r *ring[T]) len() int {
+	return r.n
+}
+
+// values returns a copy of the values, the oldest first
+func (r *ring[T]) values() []T {
+	if r.n == 0 {
+		return nil
+	}
+	values := make([]T, r.n)
+	r.copyTo(values)
+	return values
+}
+
+// copyTo copies the values to dst, the oldest first
+func (r *ring[T]) copyTo(dst []T) {
+	k := copy(dst, r.buf[r.head:min(r.head+r.n, len(r.buf))])
+	copy(dst[k:r.n], r.buf[:r.n-k])
+}
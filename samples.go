@@ -0,0 +1,33 @@
+package cc
+
+import (
+	"slices"
+	"time"
+)
+
+// sampleSize is how many durations are kept to compute percentiles
+const sampleSize = 1024
+
+// samples keeps the last sampleSize durations added in a ring buffer
+type samples struct {
+	ring [sampleSize]time.Duration
+	n    int // the number of durations added so far
+}
+
+func (s *samples) add(d time.Duration) {
+	s.ring[s.n%sampleSize] = d
+	s.n++
+}
+
+func (s *samples) percentiles() Percentiles {
+	n := min(s.n, sampleSize)
+	if n == 0 {
+		return Percentiles{}
+	}
+	sorted := slices.Clone(s.ring[:n])
+	slices.Sort(sorted)
+	at := func(q int) time.Duration {
+		return sorted[(n-1)*q/100]
+	}
+	return Percentiles{P50: at(50), P90: at(90), P99: at(99), Max: sorted[n-1]}
+}
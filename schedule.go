@@ -0,0 +1,23 @@
+package cc
+
+// Schedule is the order in which the workers pick up the functions of the same priority, see WithSchedule
+type Schedule int
+
+const (
+	// ScheduleFIFO runs the functions in submission order, the default
+	ScheduleFIFO Schedule = iota
+	// ScheduleLIFO runs the most recently submitted function first, which keeps its data warm in the caches, and
+	// shortens the wait of the recent functions under load at the expense of the older ones
+	ScheduleLIFO
+	// ScheduleRandom runs the functions in random order, which breaks the patterns of functions submitted together
+	// and contending for the same resources
+	ScheduleRandom
+)
+
+// WithSchedule sets the order in which the workers pick up the queued functions. Priorities still come first, see
+// RunPriority, and the functions waiting for their key keep their submission order, see RunKeyed.
+func WithSchedule(s Schedule) Option {
+	return func(p *Pool) {
+		p.queue.schedule = s
+	}
+}
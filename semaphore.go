@@ -0,0 +1,119 @@
+package cc
+
+import (
+	"context"
+	"sync"
+)
+
+// Semaphore limits the number of goroutines working at the same time, like the slots of a pool do: each holder takes
+// a weight out of the size of the semaphore, a weight greater than the size is capped, and the holders don't overtake
+// each other, a heavy one waits for enough free slots and the ones arriving after it wait too.
+type Semaphore struct {
+	mu      sync.Mutex
+	size    int
+	used    int
+	waiters []*semWaiter // in arrival order
+}
+
+type semWaiter struct {
+	n     int
+	ready chan struct{} // closed when the slots are given to the waiter
+}
+
+// NewSemaphore returns a semaphore of size slots, size being at least 1
+func NewSemaphore(size int) *Semaphore {
+	return &Semaphore{size: max(size, 1)}
+}
+
+// Acquire takes a slot, blocking until one is free. It returns the error of ctx if it's done first.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	return s.AcquireN(ctx, 1)
+}
+
+// AcquireN takes n slots, blocking until they are free. It returns the error of ctx if it's done first.
+func (s *Semaphore) AcquireN(ctx context.Context, n int) error {
+	s.mu.Lock()
+	n = s.weight(n)
+	if len(s.waiters) == 0 && s.used+n <= s.size {
+		s.used += n
+		s.mu.Unlock()
+		return nil
+	}
+	w := &semWaiter{n: n, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready:
+			// the slots were given meanwhile, give them back
+			s.used -= n
+		default:
+			for i, other := range s.waiters {
+				if other == w {
+					s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+					break
+				}
+			}
+		}
+		s.wake()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes a slot only if one is free, and reports whether it did
+func (s *Semaphore) TryAcquire() bool {
+	return s.TryAcquireN(1)
+}
+
+// TryAcquireN takes n slots only if they are free, and reports whether it did
+func (s *Semaphore) TryAcquireN(n int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n = s.weight(n)
+	if len(s.waiters) > 0 || s.used+n > s.size {
+		return false
+	}
+	s.used += n
+	return true
+}
+
+// Release gives back a slot taken with Acquire or TryAcquire
+func (s *Semaphore) Release() {
+	s.ReleaseN(1)
+}
+
+// ReleaseN gives back n slots taken with AcquireN or TryAcquireN. It panics if more slots are released than taken.
+func (s *Semaphore) ReleaseN(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used -= s.weight(n)
+	if s.used < 0 {
+		panic("cc: semaphore released more than acquired")
+	}
+	s.wake()
+}
+
+// weight caps n to the size of the semaphore. It must be called with s.mu held.
+func (s *Semaphore) weight(n int) int {
+	return min(max(n, 1), s.size)
+}
+
+// wake gives the free slots to the waiters, in order. It must be called with s.mu held.
+func (s *Semaphore) wake() {
+	for len(s.waiters) > 0 {
+		w := s.waiters[0]
+		if s.used+w.n > s.size {
+			return
+		}
+		s.used += w.n
+		s.waiters[0] = nil
+		s.waiters = s.waiters[1:]
+		close(w.ready)
+	}
+}
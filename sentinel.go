@@ -0,0 +1,17 @@
+package cc
+
+import "errors"
+
+// ErrDone is the last value sent to Errors by a pool created WithDoneSentinel, see there
+var ErrDone = errors.New("cc: pool done")
+
+// WithDoneSentinel makes the pool send ErrDone to Errors once all the functions ended, right before closing it, so
+// that a consumer multiplexing the Errors of several pools in a select can tell that a pool is over from a value,
+// then stop receiving from its channel, rather than from the zero value of a closed channel. Done is another way to
+// do it. The helpers consuming Errors, like WaitErr, leave ErrDone out of the errors they return, and a pool with
+// WithErrorCollector doesn't send it, as nobody consumes its Errors.
+func WithDoneSentinel() Option {
+	return func(p *Pool) {
+		p.doneSentinel = true
+	}
+}
@@ -0,0 +1,68 @@
+package cc
+
+import "context"
+
+// NewSerial returns a new pool without workers: its functions run one at a time in the goroutine submitting them,
+// before Run returns, with the same retries, hooks, stats and panic handling as the other pools. A function
+// submitted by a running function runs once the latter returns, and a function submitted while another goroutine is
+// running the functions is run by that goroutine. It makes tests deterministic and debugging easy by switching the
+// constructor only. Since nobody could consume Errors while Run is running the function, the errors are collected
+// as with WithErrorCollector, and returned by Errs and WaitErr. The queue of a serial pool is never bounded, and the
+// functions waiting for a delay, like the retries with a backoff or RunAfter, run in the goroutine of their timer.
+func NewSerial(opts ...Option) *Pool {
+	opts = append(opts[:len(opts):len(opts)], WithErrorCollector(), func(p *Pool) {
+		p.serial = true
+		p.queueSize = 0
+		p.nworkers = 1 // the goroutines submitting the functions take turns as the only worker
+		p.closers = append(p.closers, func() {
+			if p.inline != nil {
+				p.teardownWorker(p.inline)
+			}
+		})
+	})
+	return newPool(context.Background(), 1, 0, opts)
+}
+
+// runSerial runs the queued tasks of a serial pool in the calling goroutine, unless another goroutine does already
+func (p *Pool) runSerial() {
+	if !p.serial {
+		return
+	}
+	p.mu.Lock()
+	if p.inlineRunning {
+		p.mu.Unlock()
+		return
+	}
+	p.inlineRunning = true
+	w, id := p.inline, -1
+	if w == nil {
+		id = p.takeWorkerID()
+	}
+	p.mu.Unlock()
+	if w == nil {
+		w = p.initWorker(id)
+		p.inline = w // only touched by the goroutine running the tasks
+	}
+	w.goroutine = currentGoroutineID()
+	p.mu.Lock()
+	for p.runnable() {
+		t := p.claim()
+		units := t.units // t may be recycled once executed
+		p.mu.Unlock()
+		w.current = t
+		p.execute(w, t)
+		w.current = nil
+		p.releaseUnits(units)
+		if w.abandoned {
+			p.teardownWorker(w) // freeing its ID, the state being left to its task
+			p.mu.Lock()
+			id := p.takeWorkerID()
+			p.mu.Unlock()
+			w = p.initWorker(id)
+			p.inline = w
+		}
+		p.mu.Lock()
+	}
+	p.inlineRunning = false
+	p.mu.Unlock()
+}
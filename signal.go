@@ -0,0 +1,47 @@
+package cc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// StopOnSignal stops p gracefully when the process receives one of sigs, os.Interrupt if none: p stops accepting
+// new functions like with Stop, and is given grace for its functions to end before its context is cancelled. A second
+// signal cancels the context right away. The cause of the cancellation, see context.Cause, tells the signal.
+// It spares the commands the glue code, e.g. defer cc.StopOnSignal(p, 10*time.Second, os.Interrupt, syscall.SIGTERM)().
+// The returned function stops listening to the signals. Errors must still be consumed meanwhile.
+func StopOnSignal(p *Pool, grace time.Duration, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt}
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	done := make(chan struct{})
+	p.goTracked(func() {
+		defer signal.Stop(c)
+		var sig os.Signal
+		select {
+		case sig = <-c:
+		case <-p.finished:
+			return
+		case <-done:
+			return
+		}
+		p.Wait()
+		ctx, cancel := withClockTimeout(context.Background(), p.clock, grace)
+		defer cancel()
+		select {
+		case <-p.finished:
+		case <-ctx.Done():
+			p.cancelWith(ErrStopped, fmt.Errorf("cc: stopped by %v after a grace period of %s", sig, grace))
+		case sig = <-c:
+			p.cancelWith(ErrStopped, fmt.Errorf("cc: stopped by a second %v", sig))
+		case <-done:
+		}
+	})
+	return sync.OnceFunc(func() { close(done) })
+}
@@ -0,0 +1,33 @@
+package cc
+
+import "runtime"
+
+// singleThreadedBacklog is the number of queued functions per worker beyond which a submission yields to the workers
+// in single-threaded mode
+const singleThreadedBacklog = 16
+
+// WithSingleThreaded tunes the pool for the platforms running a single goroutine at a time, like js/wasm and wasip1:
+// there, a producer submitting in a loop doesn't let the workers run until it blocks, so that the queue piles up all
+// the functions first. When on is true a submission yields to the workers, with runtime.Gosched, once more than 16
+// functions per worker are queued, and RunPhased prepares the functions in the goroutine submitting them rather than
+// in a goroutine each. Unlike a bounded queue, see NewBounded, the submissions never block: the errors can still be
+// consumed once all the functions are submitted. It's off by default, on every platform.
+func WithSingleThreaded(on bool) Option {
+	return func(p *Pool) {
+		p.singleThreaded = on
+	}
+}
+
+// yieldToWorkers lets the workers run if the backlog of the pool is large in single-threaded mode, see
+// WithSingleThreaded
+func (p *Pool) yieldToWorkers() {
+	if !p.singleThreaded || p.serial {
+		return
+	}
+	p.mu.Lock()
+	backlog := p.queued() > singleThreadedBacklog*max(p.concurrency, 1)
+	p.mu.Unlock()
+	if backlog {
+		runtime.Gosched()
+	}
+}
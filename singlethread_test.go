@@ -0,0 +1,41 @@
+package cc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSingleThreadedPhased(t *testing.T) {
+	within(t, 5*time.Second, func() {
+		p := New(2, WithSingleThreaded(true))
+		for range 40 {
+			p.RunPhased(func() (func() error, error) { return func() error { return nil }, nil })
+		}
+		if err := p.WaitErr(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestSingleThreadedErrorsAfterWait(t *testing.T) {
+	within(t, 5*time.Second, func() {
+		p := New(1, WithSingleThreaded(true))
+		failure := errors.New("failure")
+		for range 40 {
+			if err := p.Go(func() error { return failure }); err != nil {
+				t.Error(err)
+			}
+		}
+		err := p.WaitErr()
+		var n int
+		for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+			if errors.Is(err, failure) {
+				n++
+			}
+		}
+		if n != 40 {
+			t.Errorf("got %d errors, want 40", n)
+		}
+	})
+}
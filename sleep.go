@@ -0,0 +1,46 @@
+package cc
+
+import (
+	"context"
+	"time"
+)
+
+// Sleep waits for d, returning nil, unless ctx is done first: then it returns the error of ctx right away, the reason
+// being available with context.Cause, see CancelError. Unlike time.Sleep it doesn't delay the shutdown of a pool when
+// called by its functions with their context, and it follows the Clock of the pool then, see WithClock.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if d <= 0 {
+		return nil
+	}
+	var clock Clock = realClock{}
+	if w := workerFrom(ctx); w != nil {
+		clock = w.pool.clock
+	}
+	wake := make(chan struct{})
+	timer := clock.AfterFunc(d, func() { close(wake) })
+	defer timer.Stop()
+	select {
+	case <-wake:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sleep waits for the delay b returns for attempt, like Sleep does, e.g. for a function retrying a call itself:
+//
+//	backoff := cc.ExponentialBackoff(100*time.Millisecond, 10*time.Second)
+//	for attempt := 1; ; attempt++ {
+//		if err = call(ctx); err == nil || attempt == 5 {
+//			return err
+//		}
+//		if err := backoff.Sleep(ctx, attempt); err != nil {
+//			return err
+//		}
+//	}
+func (b Backoff) Sleep(ctx context.Context, attempt int) error {
+	return Sleep(ctx, b(attempt))
+}
@@ -0,0 +1,89 @@
+package cc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Slot is a slot of the concurrency of a pool taken by Acquire, for code that runs in the caller's goroutine rather
+// than in a function submitted to the pool. It must be given back with Release.
+type Slot struct {
+	p       *Pool
+	t       *task
+	ctx     context.Context
+	cancel  context.CancelFunc
+	start   time.Time
+	release func()
+	once    sync.Once
+}
+
+// Acquire takes a slot of the pool, blocking until one is free like a function waiting in the queue does, e.g. for a
+// streaming copy loop that must count in the concurrency of the pool but isn't a closure. The slot is accounted for
+// like a running function: in Stats, Metrics, the events and the logs, and Wait doesn't return before it's released.
+// Acquire returns ErrPoolClosed or ErrQuotaExceeded like Run, or the error of ctx if it's done before a slot is free.
+// The options configure the slot like a function, e.g. with its metadata, see Meta.
+func (p *Pool) Acquire(ctx context.Context, opts ...TaskOption) (*Slot, error) {
+	t := (&task{ctx: ctx}).with(opts)
+	p.mu.Lock()
+	if err := p.refuse(); err != nil {
+		p.mu.Unlock()
+		return nil, err
+	}
+	p.reserve(t)
+	t.queued = t.submitted
+	p.mu.Unlock()
+
+	s := &Slot{p: p, t: t}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	stop := context.AfterFunc(p.ctx, s.cancel)
+	release, err := p.acquire(s.ctx, 1)
+	if err != nil {
+		stop()
+		s.cancel()
+		p.skipped(t)
+		p.finish(t, nil) // consuming its turn with WithOrdered
+		return nil, err
+	}
+	s.release = func() {
+		stop()
+		s.cancel()
+		release()
+	}
+	s.start = p.started(t)
+	return s, nil
+}
+
+// Context returns a context cancelled when the context given to Acquire or the context of the pool is done, or once
+// the slot is released
+func (s *Slot) Context() context.Context {
+	return s.ctx
+}
+
+// Release gives the slot back to the pool, ending its run. A non-nil err is handled like the error of a function:
+// counted as a failure and sent to Errors, wrapped in a TaskError, so Release blocks until Errors has room for it.
+// Only the first call has an effect.
+func (s *Slot) Release(err error) {
+	s.once.Do(func() {
+		p, t := s.p, s.t
+		info := TaskInfo{
+			Pool:      p.name,
+			Label:     t.label,
+			Submitted: t.submitted,
+			Start:     s.start,
+			Duration:  p.since(s.start),
+			Attempt:   1,
+			Seq:       t.id,
+			Err:       err,
+			Meta:      t.meta,
+		}
+		if err != nil {
+			info.Origin = t.where()
+			err = &TaskError{TaskInfo: info}
+		}
+		p.ended(t, info, false)
+		p.tripCircuit(t.label, err)
+		p.finish(t, err)
+		s.release()
+	})
+}
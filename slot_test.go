@@ -0,0 +1,52 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquireSlot(t *testing.T) {
+	p := New(1)
+	s, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Acquire(ctx); err == nil {
+		t.Error("a second slot was acquired beyond the concurrency")
+	}
+	s.Release(nil)
+	if s, err = p.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.Release(nil)
+	if err := p.WaitErr(); err != nil {
+		t.Error(err)
+	}
+	if st := p.Stats(); st.Completed != 2 || st.Skipped != 1 {
+		t.Errorf("stats %+v, want 2 completed and 1 skipped", st)
+	}
+}
+
+func TestAcquireOrderedFailure(t *testing.T) {
+	within(t, 5*time.Second, func() {
+		p := New(1, WithOrdered(), WithErrorBuffer(1))
+		busy, started := make(chan struct{}), make(chan struct{})
+		p.Run(func() { close(started); <-busy })
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := p.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Acquire returned %v, want the error of its context", err)
+		}
+		close(busy)
+		failure := errors.New("failure")
+		p.Go(func() error { return failure })
+		if err := p.WaitErr(); !errors.Is(err, failure) {
+			t.Errorf("WaitErr returned %v, want the error of the function submitted after Acquire", err)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package cc
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// PendingTask is a job submitted with RunJob and not done yet, see ExportQueue
+type PendingTask struct {
+	Job      Job
+	Priority int
+	Running  bool // whether the job was running, so that it may have had effects already
+}
+
+// RunJob queues job in the pool, to be run by the handler registered for its name, see Register and Dispatch.
+// Unlike the functions, the jobs can be exported from the queue with ExportQueue, and imported back into another pool.
+// The errors returned by the handler are sent to Errors, labeled with the name of the job.
+func (p *Pool) RunJob(job Job, opts ...TaskOption) error {
+	return p.pushJob(job, 0, opts)
+}
+
+// ExportQueue returns the jobs submitted with RunJob and not done yet, in submission order: the queued ones, the ones
+// waiting for a retry or for their key, and the running ones, flagged as such. It lets a command checkpoint a long
+// batch, e.g. on disk, and resume it after an interruption with ImportQueue. The functions are left out, as they
+// can't be serialized. The jobs are left in the pool.
+func (p *Pool) ExportQueue() []PendingTask {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	jobs := make([]*task, 0, len(p.jobs))
+	for t := range p.jobs {
+		jobs = append(jobs, t)
+	}
+	slices.SortFunc(jobs, func(a, b *task) int {
+		return cmp.Compare(a.id, b.id)
+	})
+	pending := make([]PendingTask, len(jobs))
+	for i, t := range jobs {
+		_, running := p.running[t]
+		pending[i] = PendingTask{Job: *t.job, Priority: t.priority, Running: running}
+	}
+	return pending
+}
+
+// ImportQueue queues tasks in the pool, in order, like RunJob does with their priority, typically the jobs exported
+// from an interrupted run with ExportQueue. It returns the error of the first job that couldn't be queued, the pool
+// being closed e.g., and the following jobs are not queued then.
+func (p *Pool) ImportQueue(tasks []PendingTask) error {
+	for _, pt := range tasks {
+		if err := p.pushJob(pt.Job, pt.Priority, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pushJob queues job with priority
+func (p *Pool) pushJob(job Job, priority int, opts []TaskOption) error {
+	t := &task{
+		label:    job.Name,
+		priority: priority,
+		job:      &job,
+		fn: func(ctx context.Context) error {
+			return Dispatch(ctx, job)
+		},
+	}
+	return p.push(t.with(opts))
+}
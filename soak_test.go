@@ -0,0 +1,77 @@
+package cc
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// soakConfig configures soakTest
+type soakConfig struct {
+	Duration    time.Duration // how long the pool runs, 10s if 0
+	Concurrency int           // the concurrency of the pool, 4 if 0
+	Retention   int           // the retention of the errors and of the memoized results, 100 if 0
+	MaxGrowth   uint64        // the growth of the heap tolerated once warmed up, in bytes, 1 MiB if 0
+}
+
+// soakTest runs a pool collecting errors and memoizing results, with WithErrorRetention and WithMemoRetention, through
+// batch after batch of functions failing, and memoized with ever new keys, for the duration of cfg. It checks that
+// the pool holds no more than the retention allows, and that the heap, measured after a garbage collection, doesn't
+// grow more than MaxGrowth between the end of the first tenth of the run and the end. It returns an error describing
+// the first violation.
+func soakTest(cfg soakConfig) error {
+	cfg.Duration = cmp.Or(cfg.Duration, 10*time.Second)
+	cfg.Concurrency = cmp.Or(cfg.Concurrency, 4)
+	cfg.Retention = cmp.Or(cfg.Retention, 100)
+	cfg.MaxGrowth = cmp.Or(cfg.MaxGrowth, 1<<20)
+
+	p := New(cfg.Concurrency, WithErrorCollector(), WithErrorRetention(cfg.Retention),
+		WithMemoize(time.Hour), WithMemoRetention(cfg.Retention))
+	defer p.Wait()
+	failure := errors.New("soak failure")
+	heap := func() uint64 {
+		var m runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&m)
+		return m.HeapAlloc
+	}
+
+	start := time.Now()
+	var baseline uint64
+	for key := 0; time.Since(start) < cfg.Duration; {
+		for range 100 {
+			key++
+			name := strconv.Itoa(key)
+			p.Go(func() error { return failure })
+			p.RunNamed(name, func() {})
+			p.RunOnce(name, func() (any, error) { return name, nil })
+		}
+		p.WaitBatch()
+		if baseline == 0 && time.Since(start) >= cfg.Duration/10 {
+			baseline = heap()
+		}
+		if n := len(p.Errs()); n > cfg.Retention {
+			return fmt.Errorf("cc: soak: %d errors kept, beyond the retention of %d", n, cfg.Retention)
+		}
+		p.mu.Lock()
+		memos := len(p.memos)
+		p.mu.Unlock()
+		if memos > cfg.Retention {
+			return fmt.Errorf("cc: soak: %d results memoized, beyond the retention of %d", memos, cfg.Retention)
+		}
+	}
+	if growth := int64(heap()) - int64(baseline); baseline > 0 && growth > int64(cfg.MaxGrowth) {
+		return fmt.Errorf("cc: soak: the heap grew by %d bytes, beyond the %d tolerated", growth, cfg.MaxGrowth)
+	}
+	return nil
+}
+
+func TestSoak(t *testing.T) {
+	if err := soakTest(soakConfig{Duration: 500 * time.Millisecond, Retention: 20}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,140 @@
+package cc
+
+import "time"
+
+// Stats is a snapshot of the activity of a pool
+type Stats struct {
+	Submitted int // functions submitted so far
+	Running   int // functions running right now
+	Queued    int // functions waiting for a worker
+	Completed int // functions that ended, with or without an error
+	Failed    int // functions that ended with an error, also counted in Completed
+	Skipped   int // functions that never ran because their context was done
+
+	DroppedErrors int // errors dropped because Errors was full, see WithErrorOverflow, or beyond WithErrorRetention
+	BufferReuses  int // buffers reused by BufferFrom
+	BufferAllocs  int // buffers allocated by BufferFrom
+	QuotaLeft     int // functions that can still be submitted, -1 without a quota, see Pool.Quota
+
+	// Busy is the time spent running functions, counting all the attempts when retrying
+	Busy time.Duration
+
+	// Wait describes the time spent in the queue by the last functions started, before a worker picked them up
+	Wait Percentiles
+}
+
+// Percentiles summarizes a distribution of durations
+type Percentiles struct {
+	P50, P90, P99, Max time.Duration
+}
+
+// Average returns the average time spent running a function that ended
+func (s Stats) Average() time.Duration {
+	if s.Completed == 0 {
+		return 0
+	}
+	return s.Busy / time.Duration(s.Completed)
+}
+
+// TaskInfo describes a run of a function that ended, see WithOnComplete and Metrics
+type TaskInfo struct {
+	Pool      string         // the name of the pool
+	Label     string         // the label of the function, if any
+	Submitted time.Time      // when the function was submitted to the pool
+	Start     time.Time      // when the attempt started
+	Duration  time.Duration  // how long the attempt ran
+	Attempt   int            // the number of the attempt, starting from 1
+	Seq       uint64         // the submission sequence number of the function in the pool, starting from 1
+	Allocs    uint64         // the bytes allocated during the attempt, see WithAllocStats
+	Err       error          // the error returned by the function
+	Origin    string         // where the function was submitted from, in failures only, see WithSubmitTrace
+	Meta      map[string]any // the metadata of the function, see Meta, not to be modified
+}
+
+// Stats returns a snapshot of the activity of the pool
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Queued = p.queued()
+	s.Wait = p.waits.percentiles()
+	s.BufferReuses = int(p.bufferHits.Load())
+	s.BufferAllocs = int(p.bufferMisses.Load())
+	s.QuotaLeft = p.quotaLeft()
+	s.Submitted = int(p.submissions)
+	return s
+}
+
+// started records the start of a function, and returns the start time
+func (p *Pool) started(t *task) time.Time {
+	start := p.clock.Now()
+	wait := start.Sub(t.queued)
+	p.mu.Lock()
+	p.stats.Running++
+	if t.beat != nil {
+		t.beat = &TaskContext{clock: p.clock} // a new one for each attempt, see RunHeartbeat
+		t.beat.last.Store(start.UnixNano())   // the first heartbeat, not to be stale before reaching the function
+	}
+	p.running[t] = start
+	p.waits.add(wait)
+	p.mu.Unlock()
+	if p.metrics != nil {
+		p.metrics.AddRunning(1)
+	}
+	if p.waitThreshold > 0 && wait > p.waitThreshold {
+		p.onSlowWait(t.label, wait)
+	}
+	p.logStart(t)
+	p.emit(EventStarted, t, start, nil)
+	return start
+}
+
+// ended records the end of a run of a function. A function that is about to be retried is not counted
+// as completed yet.
+func (p *Pool) ended(t *task, info TaskInfo, retried bool) {
+	p.mu.Lock()
+	p.stats.Running--
+	delete(p.running, t)
+	p.stats.Busy += info.Duration
+	if !retried {
+		p.stats.Completed++
+		if info.Err != nil {
+			p.stats.Failed++
+		}
+	}
+	p.mu.Unlock()
+	if p.metrics != nil {
+		p.metrics.AddRunning(-1)
+		p.metrics.ObserveTask(info)
+	}
+	p.logEnd(info, retried)
+	switch {
+	case retried:
+		p.emitEnd(EventRetried, info)
+	case info.Err != nil:
+		p.emitEnd(EventFailed, info)
+	default:
+		p.emitEnd(EventFinished, info)
+	}
+	if !retried && p.onComplete != nil {
+		p.onComplete(info)
+	}
+	if !retried {
+		p.completed(t, info)
+	}
+}
+
+// skipped records a function that won't run because its context is done. If a previous attempt failed,
+// the function is counted as failed instead.
+func (p *Pool) skipped(t *task) {
+	p.logSkip(t)
+	p.emit(EventSkipped, t, p.clock.Now(), t.err)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t.err != nil {
+		p.stats.Completed++
+		p.stats.Failed++
+		return
+	}
+	p.stats.Skipped++
+}
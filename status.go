@@ -0,0 +1,57 @@
+package cc
+
+import (
+	"context"
+	"fmt"
+)
+
+// Status is implemented by the results that carry their own success, like the status reports mixing warnings and
+// partial successes, see StatusPool
+type Status interface {
+	// Fail reports whether the status is a failure
+	Fail() bool
+}
+
+// StatusError is the error of a function whose status is a failure, see StatusPool
+type StatusError[S Status] struct {
+	Status S
+}
+
+func (e *StatusError[S]) Error() string {
+	if err, ok := any(e.Status).(error); ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("cc: failed with status %v", e.Status)
+}
+
+// StatusPool is a Pool whose functions return a Status instead of an error. All the statuses are sent on Statuses,
+// and the failures are handled by the pool like errors are: they are retried with WithRetry, counted in Stats, stop
+// a pool created with WithFailFast and are reported by FirstError as a *StatusError. Only the panics go to Errors.
+// Both channels must be consumed, and both are closed by Wait once all the functions end.
+type StatusPool[S Status] struct {
+	*Pool
+	Statuses chan S
+}
+
+// NewStatusPool returns a new StatusPool where a limited number (concurrency) of goroutine can work at the same time
+func NewStatusPool[S Status](concurrency int, opts ...Option) *StatusPool[S] {
+	p := &StatusPool[S]{
+		Pool:     New(concurrency, opts...),
+		Statuses: make(chan S),
+	}
+	p.closers = append(p.closers, func() { close(p.Statuses) })
+	return p
+}
+
+// RunStatus runs fn like Run does, and sends the status it returns to Statuses. When retrying only the status of the
+// last attempt is sent.
+func (p *StatusPool[S]) RunStatus(fn func() S) error {
+	return p.push(&task{outputFailures: true, produce: func(context.Context) (func(), error) {
+		s := fn()
+		output := func() { p.Statuses <- s }
+		if s.Fail() {
+			return output, &StatusError[S]{Status: s}
+		}
+		return output, nil
+	}})
+}
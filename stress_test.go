@@ -0,0 +1,174 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stressConfig configures stressTest
+type stressConfig struct {
+	Tasks       int    // the number of functions submitted, 1000 if 0
+	Concurrency int    // the initial concurrency of the pool, 4 if 0
+	Submitters  int    // the number of goroutines submitting the functions at the same time, 4 if 0
+	Seed        uint64 // the seed of the random choices, to reproduce a run
+	Stop        bool   // whether to close the pool with a Stop that times out, instead of Wait
+}
+
+// stressError is the error of a function of stressTest, identifying it
+type stressError int
+
+func (e stressError) Error() string {
+	return fmt.Sprintf("stress task %d failed", int(e))
+}
+
+// stressTest runs a pool through random interleavings of Run, Go, RunWeighted and RunCtx, submitted from Submitters
+// goroutines, with functions failing, panicking or being cancelled, while it's resized by the submitters and closed by
+// others halfway through. It checks that the functions running never take more slots than the concurrency allows, and
+// that the outcome of each function is reported exactly once, either by running it, sending its error, counting it as
+// skipped, or refusing it as the pool is closed. It returns an error describing the first violation. The interleavings
+// depend on the scheduling of the goroutines too: the same Seed makes the same choices, not the same run.
+func stressTest(cfg stressConfig) error {
+	if cfg.Tasks <= 0 {
+		cfg.Tasks = 1000
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.Submitters <= 0 {
+		cfg.Submitters = 4
+	}
+	rnd := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed>>32))
+
+	var limit, used atomic.Int64 // the highest concurrency so far, and the slots taken
+	var violation atomic.Value
+	limit.Store(int64(cfg.Concurrency))
+	raise := func(n int64) {
+		for l := limit.Load(); n > l && !limit.CompareAndSwap(l, n); l = limit.Load() {
+		}
+	}
+	outcomes := make([]atomic.Int32, cfg.Tasks)
+
+	p := New(cfg.Concurrency, WithErrorBuffer(cfg.Tasks))
+	slot := func(weight int) func() {
+		return func() {
+			w := int64(min(weight, cfg.Concurrency))
+			if n := used.Add(w); n > limit.Load() {
+				violation.CompareAndSwap(nil, fmt.Errorf("cc: stress: %d slots taken out of %d", n, limit.Load()))
+			}
+			time.Sleep(time.Duration(rand.N(100)) * time.Microsecond)
+			used.Add(-w)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	submit := func(rnd *rand.Rand, id int) error {
+		run := slot(1)
+		switch rnd.IntN(7) {
+		case 0:
+			return p.Run(func() { run(); outcomes[id].Add(1) })
+		case 1:
+			return p.Go(func() error { run(); return stressError(id) })
+		case 2:
+			return p.Go(func() error { run(); panic(stressError(id)) })
+		case 3:
+			weight := 1 + rnd.IntN(3)
+			run = slot(weight)
+			return p.RunWeighted(weight, func() { run(); outcomes[id].Add(1) })
+		case 4:
+			return p.RunCtx(cancelled, func(context.Context) error { outcomes[id].Add(1); return nil })
+		case 5:
+			// shrinking is only effective once the running functions end, keep the highest limit. Never going below
+			// the initial concurrency keeps the weights capped the same way.
+			n := cfg.Concurrency + rnd.IntN(cfg.Concurrency+1)
+			raise(int64(n))
+			p.Resize(n)
+			return p.Run(func() { run(); outcomes[id].Add(1) })
+		default:
+			return p.Go(func() error { run(); outcomes[id].Add(1); return nil })
+		}
+	}
+
+	// the pool is closed once closeAt functions were submitted, by two goroutines at once
+	closeAt, closing := int64(cfg.Tasks/2+rnd.IntN(cfg.Tasks/2+1)), make(chan struct{})
+	timeout := time.Duration(rnd.N(int64(time.Millisecond)))
+	var submitted atomic.Int64
+	var wg sync.WaitGroup
+	for i := range cfg.Submitters {
+		rnd := rand.New(rand.NewPCG(cfg.Seed+uint64(i)+1, cfg.Seed>>32))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := i; id < cfg.Tasks; id += cfg.Submitters {
+				if err := submit(rnd, id); err != nil {
+					if !errors.Is(err, ErrPoolClosed) {
+						violation.CompareAndSwap(nil, fmt.Errorf("cc: stress: unexpected refusal: %w", err))
+					}
+					outcomes[id].Add(1)
+				}
+				if submitted.Add(1) == closeAt {
+					close(closing)
+				}
+			}
+		}()
+	}
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-closing
+			if cfg.Stop {
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				p.Stop(ctx)
+				cancel()
+			} else {
+				p.Wait()
+			}
+		}()
+	}
+	wg.Wait()
+	for err := range p.Errors {
+		var se stressError
+		var pe *PanicError
+		switch {
+		case errors.As(err, &pe):
+			se, _ = pe.Value.(stressError)
+		case !errors.As(err, &se):
+			return fmt.Errorf("cc: stress: unexpected error: %w", err)
+		}
+		outcomes[se].Add(1)
+	}
+
+	if err, _ := violation.Load().(error); err != nil {
+		return err
+	}
+	missing := 0
+	for id := range outcomes {
+		switch n := outcomes[id].Load(); {
+		case n > 1:
+			return fmt.Errorf("cc: stress: outcome of task %d reported %d times", id, n)
+		case n == 0:
+			missing++
+		}
+	}
+	if skipped := p.Stats().Skipped; missing != skipped {
+		return fmt.Errorf("cc: stress: %d outcomes missing, but %d functions skipped", missing, skipped)
+	}
+	return nil
+}
+
+func TestStress(t *testing.T) {
+	for seed := range uint64(8) {
+		for _, stop := range []bool{false, true} {
+			if err := stressTest(stressConfig{Tasks: 500, Seed: seed, Stop: stop}); err != nil {
+				t.Errorf("seed %d, stop %v: %v", seed, stop, err)
+			}
+		}
+	}
+}
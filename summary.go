@@ -0,0 +1,139 @@
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrorSummary aggregates errors, grouping the identical ones: the errors of the functions with the same label whose
+// errors have the same type and message. It's returned by WaitSummary and Summarize, and its message lists the groups
+// with their count, rather than every error.
+type ErrorSummary struct {
+	Groups []ErrorGroup // in the order of their first error
+	Total  int          // the number of errors
+
+	Retryable, Permanent int // the number of errors marked with Retryable and Permanent
+}
+
+// ErrorGroup is a group of identical errors in an ErrorSummary
+type ErrorGroup struct {
+	Label string // the label of the functions that failed, if any
+	Err   error  // the first error of the group
+	Count int
+	Class ErrorClass // whether the errors are worth retrying, see Classify
+	First time.Time  // when the first error happened, if known
+	Last  time.Time  // when the last error happened, if known
+}
+
+// Summarize returns the summary of errs, or nil if there is none. The time of the errors is known for the ones of the
+// functions of a pool, see TaskError.
+func Summarize(errs []error) *ErrorSummary {
+	var s summarizer
+	for _, err := range errs {
+		s.add(err, time.Time{})
+	}
+	return s.summary()
+}
+
+// WaitSummary is like WaitErr, but it returns the errors as an *ErrorSummary, or nil if there was none. The errors
+// that aren't from a function of the pool are timed when they are received from Errors.
+func (p *Pool) WaitSummary() error {
+	p.Wait()
+	var s summarizer
+	for err := range p.Errors {
+		if err != ErrDone {
+			s.add(err, p.clock.Now())
+		}
+	}
+	if summary := s.summary(); summary != nil {
+		return summary
+	}
+	return nil
+}
+
+func (s *ErrorSummary) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "cc: %d errors", s.Total)
+	if len(s.Groups) > 1 {
+		fmt.Fprintf(&b, " of %d kinds", len(s.Groups))
+	}
+	b.WriteString(":")
+	for _, g := range s.Groups {
+		fmt.Fprintf(&b, "\n  %d× %s", g.Count, g.Err)
+		if g.Class != ClassUnmarked {
+			fmt.Fprintf(&b, " [%s]", g.Class)
+		}
+		switch {
+		case g.First.IsZero():
+		case g.Count == 1:
+			fmt.Fprintf(&b, " (at %s)", g.First.Format(time.TimeOnly))
+		default:
+			fmt.Fprintf(&b, " (from %s to %s)", g.First.Format(time.TimeOnly), g.Last.Format(time.TimeOnly))
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the first error of each group, for errors.Is and errors.As
+func (s *ErrorSummary) Unwrap() []error {
+	errs := make([]error, len(s.Groups))
+	for i, g := range s.Groups {
+		errs[i] = g.Err
+	}
+	return errs
+}
+
+// summarizer builds an ErrorSummary
+type summarizer struct {
+	groups []ErrorGroup
+	index  map[string]int // the groups by key
+}
+
+// add adds err to its group. at is the time of err, unless it's from a function of a pool.
+func (s *summarizer) add(err error, at time.Time) {
+	label, cause := "", err
+	var terr *TaskError
+	if errors.As(err, &terr) {
+		label, cause = terr.Label, terr.Err
+		at = terr.Start.Add(terr.Duration)
+	}
+	class := Classify(err)
+	cause = unclassified(cause)
+	key := fmt.Sprintf("%s\x00%d\x00%T\x00%s", label, class, cause, cause)
+	i, ok := s.index[key]
+	if !ok {
+		if s.index == nil {
+			s.index = map[string]int{}
+		}
+		i = len(s.groups)
+		s.index[key] = i
+		s.groups = append(s.groups, ErrorGroup{Label: label, Err: err, Class: class, First: at})
+	}
+	g := &s.groups[i]
+	g.Count++
+	if g.First.IsZero() || at.Before(g.First) {
+		g.First = at
+	}
+	if at.After(g.Last) {
+		g.Last = at
+	}
+}
+
+func (s *summarizer) summary() *ErrorSummary {
+	if len(s.groups) == 0 {
+		return nil
+	}
+	summary := &ErrorSummary{Groups: s.groups}
+	for _, g := range s.groups {
+		summary.Total += g.Count
+		switch g.Class {
+		case ClassRetryable:
+			summary.Retryable += g.Count
+		case ClassPermanent:
+			summary.Permanent += g.Count
+		}
+	}
+	return summary
+}
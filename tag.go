@@ -0,0 +1,83 @@
+package cc
+
+import "context"
+
+// Tagged submits functions to a pool under a tag, so that they can be waited on apart from the rest, see Pool.Tag
+type Tagged struct {
+	p   *Pool
+	tag string
+}
+
+// tagState tracks the functions of a tag still pending
+type tagState struct {
+	pending int
+	waiters []chan struct{} // closed when no function of the tag is pending anymore
+}
+
+// Tag returns a handle submitting functions to the pool under tag, e.g. p.Tag("uploads").Run(fn), so that WaitTag
+// can wait on them while the pool keeps running other functions. It spares creating a pool for each phase of the work.
+func (p *Pool) Tag(tag string) *Tagged {
+	return &Tagged{p: p, tag: tag}
+}
+
+// Run is like Pool.Run, fn having the tag of tg
+func (tg *Tagged) Run(fn func()) error {
+	t := plainTask(fn)
+	t.tag = tg.tag
+	return tg.p.push(t)
+}
+
+// RunCtx is like Pool.RunCtx, fn having the tag of tg
+func (tg *Tagged) RunCtx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return tg.p.push(&task{ctx: ctx, fn: fn, tag: tg.tag})
+}
+
+// Go is like Pool.Go, fn having the tag of tg
+func (tg *Tagged) Go(fn func() error) error {
+	return tg.p.push(&task{tag: tg.tag, fn: func(context.Context) error {
+		return fn()
+	}})
+}
+
+// WaitTag blocks until all the functions submitted so far under tag end, including their retries, returning right
+// away if there is none. Unlike WaitBatch the errors are left in Errors, which must still be consumed meanwhile.
+func (p *Pool) WaitTag(tag string) {
+	p.mu.Lock()
+	ts := p.tags[tag]
+	if ts == nil {
+		p.mu.Unlock()
+		return
+	}
+	c := make(chan struct{})
+	ts.waiters = append(ts.waiters, c)
+	p.mu.Unlock()
+	<-c
+}
+
+// holdTag counts t as pending in its tag, if it has one. It must be called with p.mu held.
+func (p *Pool) holdTag(t *task) {
+	if t.tag == "" {
+		return
+	}
+	ts := p.tags[t.tag]
+	if ts == nil {
+		ts = &tagState{}
+		p.tags[t.tag] = ts
+	}
+	ts.pending++
+}
+
+// releaseTag marks t as done in its tag, if it has one, waking up WaitTag. It must be called with p.mu held.
+func (p *Pool) releaseTag(t *task) {
+	if t.tag == "" {
+		return
+	}
+	ts := p.tags[t.tag]
+	if ts.pending--; ts.pending > 0 {
+		return
+	}
+	for _, c := range ts.waiters {
+		close(c)
+	}
+	delete(p.tags, t.tag)
+}
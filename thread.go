@@ -0,0 +1,19 @@
+package cc
+
+// WithLockOSThread makes each worker of the pool lock its goroutine to its own OS thread for its whole life, see
+// runtime.LockOSThread, so that the functions it runs, its init hook and its teardown hook (see WithWorkerInit) all
+// run on the same thread, as cgo libraries and serial drivers with thread affinity need. To dedicate only part of
+// the workers to such functions, use a child pool with this option, see Child.
+func WithLockOSThread() Option {
+	return func(p *Pool) {
+		p.lockOSThread = true
+	}
+}
+
+// RunLocked is like Run, but fn runs locked to the OS thread of its worker, which is unlocked once fn returns or
+// panics. A function that must find the same thread over several runs needs WithLockOSThread instead.
+func (p *Pool) RunLocked(fn func()) error {
+	t := plainTask(fn)
+	t.locked = true
+	return p.push(t)
+}
@@ -0,0 +1,98 @@
+package cc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Ticker runs a function periodically in a pool, see RunEvery
+type Ticker struct {
+	pool     *Pool
+	fn       func(ctx context.Context) error
+	ctx      context.Context
+	cancel   context.CancelFunc
+	coalesce bool
+
+	mu      sync.Mutex
+	running bool // whether a run is queued or running
+	missed  bool // whether a tick happened during the current run, when coalescing
+}
+
+// TickerOption configures a Ticker, see RunEvery
+type TickerOption func(*Ticker)
+
+// WithCoalesce makes a Ticker run its function once more, right after the current run ends, when one or more ticks
+// happen during the run. By default those ticks are skipped.
+func WithCoalesce() TickerOption {
+	return func(t *Ticker) {
+		t.coalesce = true
+	}
+}
+
+// RunEvery runs fn in p every interval, like RunCtx does, until the returned Ticker is stopped or p is closed. A run
+// never overlaps with the previous one: the ticks happening while fn is still queued or running are skipped, or
+// coalesced with WithCoalesce. The context of fn is cancelled when the Ticker is stopped, and when p is cancelled.
+func (p *Pool) RunEvery(interval time.Duration, fn func(ctx context.Context) error, opts ...TickerOption) *Ticker {
+	t := &Ticker{pool: p, fn: fn}
+	t.ctx, t.cancel = context.WithCancel(p.ctx)
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.pool.goTracked(func() { t.loop(interval) })
+	return t
+}
+
+// Stop stops the Ticker. A run that already started is not waited for, but its context is cancelled.
+func (t *Ticker) Stop() {
+	t.cancel()
+}
+
+func (t *Ticker) loop(interval time.Duration) {
+	ticks, stop := tick(t.pool.clock, interval)
+	defer stop()
+	for {
+		select {
+		case <-ticks:
+			t.tick()
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// tick queues a run, unless the previous one is still queued or running
+func (t *Ticker) tick() {
+	t.mu.Lock()
+	if t.running {
+		t.missed = t.coalesce
+		t.mu.Unlock()
+		return
+	}
+	t.running = true
+	t.mu.Unlock()
+	t.run()
+}
+
+func (t *Ticker) run() {
+	err := t.pool.push(&task{ctx: t.ctx, fn: func(ctx context.Context) error {
+		defer t.ended()
+		return t.fn(ctx)
+	}})
+	if err != nil {
+		// the pool is closed
+		t.cancel()
+	}
+}
+
+// ended marks the end of a run, queueing the next one right away if a tick was missed
+func (t *Ticker) ended() {
+	t.mu.Lock()
+	again := t.missed
+	t.missed = false
+	t.running = again
+	t.mu.Unlock()
+	if again {
+		t.pool.goTracked(t.run)
+	}
+}
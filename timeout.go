@@ -0,0 +1,98 @@
+package cc
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The context of a function run by a pool expires at the earliest of:
+//   - the deadline of the context of the pool, see NewWithContext and WithContext,
+//   - the deadline of the context the function was submitted with, see RunCtx,
+//   - the timeout of the function, counted from its start: the one given with RunWithTimeout or SubmitTimeout,
+//     or else the one of the pool given with WithTaskTimeout.
+//
+// Stop and WaitContext don't set deadlines: when the context given to Stop is done the context of the pool is
+// cancelled, while WaitContext just stops waiting. The effective deadline of a function is exposed by Task.Deadline.
+
+// RunWithTimeout is like RunCtx, but the context given to fn also expires d after fn starts. If the deadline is hit
+// the error sent to Errors wraps context.DeadlineExceeded, even if fn ignored the cancellation and returned nil.
+func (p *Pool) RunWithTimeout(d time.Duration, fn func(ctx context.Context) error) error {
+	return p.push(&task{fn: fn, timeout: d})
+}
+
+// WithTaskTimeout makes the context given to the functions of the pool expire d after they start, like RunWithTimeout
+// does, unless they have their own timeout. The functions taking no context, like the ones of Run, are not concerned.
+func WithTaskTimeout(d time.Duration) Option {
+	return func(p *Pool) {
+		p.taskTimeout = d
+	}
+}
+
+// SubmitTimeout is like Submit, but the context given to fn also expires d after fn starts, like RunWithTimeout does
+func SubmitTimeout[T any](p *Pool, d time.Duration, fn func(ctx context.Context) (T, error)) *Task[T] {
+	t := newTask(p, fn)
+	t.task.timeout = d
+	if err := p.push(t.task); err != nil {
+		t.task.onDone(err)
+	}
+	return t
+}
+
+// Deadline returns the effective deadline of the function of the task, and false if it has none. Until the function
+// starts its own timeout isn't counted yet, see RunWithTimeout.
+func (t *Task[T]) Deadline() (time.Time, bool) {
+	p := t.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !t.task.deadline.IsZero() {
+		return t.task.deadline, true
+	}
+	return earliest(t.task.ctx, p.ctx, time.Time{})
+}
+
+// withDeadline makes ctx, the context of t starting at start, expire after the timeout of t, if any. It returns the
+// timeout, and a function releasing the resources of ctx.
+func (p *Pool) withDeadline(ctx context.Context, t *task, start time.Time) (context.Context, time.Duration, context.CancelFunc) {
+	timeout := cmp.Or(t.timeout, p.taskTimeout)
+	if timeout <= 0 || t.run != nil {
+		return ctx, 0, func() {}
+	}
+	ctx, cancel := withClockTimeoutCause(ctx, p.clock, timeout, &CancelError{Reason: ErrTaskTimeout, Err: context.DeadlineExceeded})
+	if d, ok := earliest(ctx, p.ctx, start.Add(timeout)); ok {
+		p.mu.Lock()
+		t.deadline = d
+		p.mu.Unlock()
+	}
+	return ctx, timeout, cancel
+}
+
+// earliest returns the earliest of the deadlines of a and b, and of at unless it's zero, and false if there is none
+func earliest(a, b context.Context, at time.Time) (time.Time, bool) {
+	for _, ctx := range []context.Context{a, b} {
+		if ctx == nil {
+			continue
+		}
+		if d, ok := ctx.Deadline(); ok && (at.IsZero() || d.Before(at)) {
+			at = d
+		}
+	}
+	return at, !at.IsZero()
+}
+
+// timedOut returns the error of a function whose context ctx expired after d, wrapping context.DeadlineExceeded,
+// or err if ctx didn't expire
+func timedOut(ctx context.Context, d time.Duration, err error) error {
+	if cause := context.Cause(ctx); ctx.Err() == nil || cause != context.DeadlineExceeded && !cancelledBy(cause, ErrTaskTimeout) {
+		return err
+	}
+	switch {
+	case err == nil:
+		err = context.DeadlineExceeded
+	case !errors.Is(err, context.DeadlineExceeded):
+		err = fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+	}
+	return fmt.Errorf("cc: timed out after %s: %w", d, err)
+}
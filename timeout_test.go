@@ -0,0 +1,38 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	p := New(2)
+	errs := drain(p)
+	p.RunWithTimeout(time.Millisecond, func(context.Context) error {
+		time.Sleep(20 * time.Millisecond) // ignoring the deadline and returning nil
+		return nil
+	})
+	p.RunWithTimeout(time.Second, func(context.Context) error { return nil })
+	p.Wait()
+	got := errs()
+	if len(got) != 1 || !errors.Is(got[0], context.DeadlineExceeded) {
+		t.Errorf("got errors %v, want the deadline of the slow function only", got)
+	}
+}
+
+func TestRunWithTimeoutCancels(t *testing.T) {
+	p := New(1)
+	errs := drain(p)
+	failure := errors.New("interrupted")
+	p.RunWithTimeout(time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return failure
+	})
+	p.Wait()
+	got := errs()
+	if len(got) != 1 || !errors.Is(got[0], context.DeadlineExceeded) || !errors.Is(got[0], failure) {
+		t.Errorf("got errors %v, want the error of the function wrapped with the deadline", got)
+	}
+}
@@ -0,0 +1,49 @@
+package cc
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer creates the spans of the functions run by a pool, so that they can be traced with OpenTelemetry or any other
+// distributed tracing system without this package depending on it. Implementations must be safe for concurrent use.
+type Tracer interface {
+	// Start starts the span of a run of a function, described by info. ctx is the context the function was submitted
+	// with, so that the span can be a child of the span of the submitter. The returned context is given to the function.
+	Start(ctx context.Context, info TaskInfo) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	// AddEvent records an event that happened at the given time
+	AddEvent(name string, at time.Time)
+	// End ends the span, err being the error returned by the function
+	End(err error)
+}
+
+// WithTracer makes the pool start a span with t for each run of a function. The span gets a "cc.queued" event at the
+// time the function was submitted and a "cc.started" one when it started, the difference being the time spent waiting
+// for a worker, and ends when the function returns.
+func WithTracer(t Tracer) Option {
+	return func(p *Pool) {
+		p.tracer = t
+	}
+}
+
+// startSpan starts the span of t with the tracer of the pool, if any
+func (p *Pool) startSpan(ctx context.Context, t *task, start time.Time) (context.Context, Span) {
+	if p.tracer == nil {
+		return ctx, nil
+	}
+	ctx, span := p.tracer.Start(ctx, TaskInfo{
+		Pool:      p.name,
+		Label:     t.label,
+		Submitted: t.submitted,
+		Start:     start,
+		Attempt:   t.attempt + 1,
+		Meta:      t.meta,
+	})
+	span.AddEvent("cc.queued", t.submitted)
+	span.AddEvent("cc.started", start)
+	return ctx, span
+}
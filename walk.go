@@ -0,0 +1,55 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+)
+
+// WalkDir walks the file tree rooted at root like filepath.WalkDir, and calls fn on each file with at most concurrency
+// calls at the same time. fn is called on the directories by the walk itself, before their entries are walked, so that
+// it can return fs.SkipDir to skip them or fs.SkipAll to stop the walk. The walk waits while concurrency files are
+// already waiting, so that the memory stays bounded on huge trees. WalkDir stops when ctx is done, and returns the
+// errors of the walk and of fn joined with errors.Join, along with the error of ctx if it was cancelled. It stops as well
+// if the pool refuses a file, and returns the reason among the errors.
+func WalkDir(ctx context.Context, root string, concurrency int, fn func(path string, d fs.DirEntry) error) error {
+	p := New(concurrency, WithContext(ctx), WithQueueSize(concurrency), WithErrorCollector())
+	var errs []error
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fs.SkipAll
+		}
+		if d.IsDir() {
+			err := fn(path, d)
+			if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+				return err
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+			return nil
+		}
+		if err := p.push(&task{label: path, fn: func(context.Context) error {
+			return fn(path, d)
+		}}); err != nil {
+			errs = append(errs, err)
+			return fs.SkipAll
+		}
+		return nil
+	})
+	p.Wait()
+	<-p.finished
+	errs = append(append(errs, err), p.Errs()...)
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
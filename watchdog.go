@@ -0,0 +1,46 @@
+package cc
+
+import "time"
+
+type watchdog struct {
+	interval time.Duration
+	fn       func(blocked int)
+}
+
+// WithWatchdog makes the pool check every d whether all its busy workers are stuck sending errors to Errors, which
+// happens when nobody consumes it. When they are, and no error got through since the previous check, fn is called
+// with the number of blocked workers. fn is called once per stall.
+func WithWatchdog(d time.Duration, fn func(blocked int)) Option {
+	return func(p *Pool) {
+		p.watchdog = &watchdog{interval: d, fn: fn}
+	}
+}
+
+// watchdogLoop runs the watchdog of the pool until the pool is finished
+func (p *Pool) watchdogLoop() {
+	ticks, stop := tick(p.clock, p.watchdog.interval)
+	defer stop()
+	lastSent, fired := -1, false
+	for {
+		select {
+		case <-ticks:
+		case <-p.finished:
+			return
+		}
+		p.mu.Lock()
+		blocked := p.sending
+		busy := p.nworkers - p.idleWorkers
+		sent := p.sent
+		p.mu.Unlock()
+
+		stalled := blocked > 0 && blocked >= busy && sent == lastSent
+		lastSent = sent
+		switch {
+		case !stalled:
+			fired = false
+		case !fired:
+			fired = true
+			p.watchdog.fn(blocked)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package cc
+
+// RunWeighted is like Run, but fn takes weight slots out of the concurrency of the pool while it runs, so that a pool of
+// concurrency 8 can run either 8 functions of weight 1 or 2 functions of weight 4 at the same time. A weight greater
+// than the concurrency of the pool is capped, so that the function runs alone.
+// Functions don't overtake each other: a heavy function waits for enough slots, and the functions queued after it wait too.
+func (p *Pool) RunWeighted(weight int, fn func()) error {
+	t := plainTask(fn)
+	t.weight = weight
+	return p.push(t)
+}
+
+// runnable reports whether the next task of the queue fits in the free slots, and the pool isn't paused.
+// The slots held back by WithLowPriority are not free.
+// It must be called with p.mu held.
+func (p *Pool) runnable() bool {
+	if p.paused && p.ctx.Err() == nil {
+		return false
+	}
+	next := p.replayed()
+	limit := max(p.concurrency-p.throttled, 1)
+	return next != nil && p.used+min(max(next.weight, 1), limit) <= limit
+}
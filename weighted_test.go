@@ -0,0 +1,36 @@
+package cc
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunWeighted(t *testing.T) {
+	p := New(4)
+	var mu sync.Mutex
+	used, high := 0, 0
+	for i := range 30 {
+		weight := []int{1, 2, 3, 9}[i%4]
+		err := p.RunWeighted(weight, func() {
+			units := min(weight, 4) // the function of weight 9 must run alone
+			mu.Lock()
+			used += units
+			high = max(high, used)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			used -= units
+			mu.Unlock()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+	if high > 4 {
+		t.Errorf("took %d slots out of 4 at the same time", high)
+	}
+}
@@ -0,0 +1,559 @@
+package cc
+
+import (
+	"cmp"
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// task is a function queued in a pool, together with the context it was submitted with (if any)
+type task struct {
+	ctx       context.Context
+	fn        func(ctx context.Context) error
+	run       func() // the function when it takes no context and returns no error, to spare a closure
+	recycle   bool   // whether the task goes back to taskPool once done, nothing referencing it anymore
+	label     string
+	submitted time.Time
+	queued    time.Time // when the task was queued last, differs from submitted when retrying
+	attempt   int
+	err       error // the error of the previous attempt
+
+	beat      *TaskContext // the context receiving the heartbeats, see RunHeartbeat
+	goroutine uint64       // the ID of the goroutine running the task, see Dump
+
+	// onDone receives the final outcome of the task instead of Errors, see Submit
+	onDone func(err error)
+	// cleanup is called once the task is over, whether it ran or not, see RunWithCleanup
+	cleanup func()
+	// produce is the function of a task producing a value, instead of fn, returning how to deliver the value
+	produce func(ctx context.Context) (output func(), err error)
+	// output delivers the value produced by the task if it succeeds, see PoolOf
+	output func()
+	// outputFailures makes the value delivered instead of the error if the task fails, see StatusPool
+	outputFailures bool
+	order          uint64 // the order of submission, among the tasks without onDone
+	id             uint64 // the order of submission, among all the tasks, starting from 1
+
+	timer Timer // the timer queueing the task, see RunAfter
+	keyed bool  // whether the task has a key, see RunKeyed
+	key   string
+	class string // the class limiting the task, see RunClass
+
+	timeout  time.Duration // the timeout of the task, see RunWithTimeout
+	deadline time.Time     // the effective deadline of the task once started, see Task.Deadline
+	priority int
+	seq      uint64 // the order of submission in the queue
+	round    uint64 // the turn of the submitter of the task, see WithFairness
+
+	submitter string
+	index     int // the position in the queue, -1 when not queued
+
+	size   int64 // the memory held while queued, see RunSized
+	weight int   // the number of slots needed, see RunWeighted
+	units  int   // the number of slots taken while running
+
+	locked bool           // whether the task runs locked to its OS thread, see RunLocked
+	meta   map[string]any // see Meta
+	job    *Job           // the job run by the task, see RunJob
+
+	origin []uintptr // the stack submitting the task, see WithSubmitTrace
+	tag    string    // the tag of the task, see Pool.Tag
+}
+
+// taskPool recycles the tasks of the plain functions, so that running them doesn't allocate
+var taskPool = sync.Pool{New: func() any { return new(task) }}
+
+// plainTask returns a recycled task running fn
+func plainTask(fn func()) *task {
+	t := taskPool.Get().(*task)
+	t.run = fn
+	t.recycle = true
+	return t
+}
+
+// NewBounded returns a new pool like New does, but its queue holds at most queueSize functions waiting for a worker.
+// When the queue is full Run blocks until a worker frees a spot, so that producers can't get too far ahead of the workers.
+func NewBounded(concurrency, queueSize int, opts ...Option) *Pool {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return newPool(context.Background(), concurrency, queueSize, opts)
+}
+
+// push queues t, blocking while the queue is full. It returns ErrPoolClosed if the pool is closed, or ErrQuotaExceeded.
+func (p *Pool) push(t *task) error {
+	if p.submitLimiter != nil {
+		p.submitLimiter.wait(p.ctx) // it only fails if the pool is cancelled, then t is skipped anyway
+	}
+	p.mu.Lock()
+	for p.state == stateOpen && p.queueSize > 0 && p.queued() >= p.queueSize {
+		p.notFull.Wait()
+	}
+	if err := p.refuse(); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	if err := p.checkCircuit(t); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	victims, err := p.makeRoom(t)
+	if err != nil {
+		p.mu.Unlock()
+		p.evicted(t, nil)
+		return err
+	}
+	p.enqueue(t)
+	label, at := t.label, t.submitted // t may be done and recycled once unlocked
+	p.mu.Unlock()
+	p.evicted(nil, victims)
+	p.emitSubmitted(label, at)
+	p.runSerial()
+	p.yieldToWorkers()
+	return nil
+}
+
+// tryPush queues t only if it can start right away, or if there is room in the queue of a bounded pool.
+// It returns false otherwise, and if the pool is closed.
+func (p *Pool) tryPush(t *task) bool {
+	if p.submitLimiter != nil && !p.submitLimiter.allow() {
+		return false
+	}
+	p.mu.Lock()
+	full := p.queued() >= p.concurrency-p.used
+	if p.queueSize > 0 {
+		full = p.queued() >= p.queueSize
+	}
+	if p.refuse() != nil || full || p.checkCircuit(t) != nil {
+		p.mu.Unlock()
+		return false
+	}
+	victims, err := p.makeRoom(t)
+	if err != nil {
+		p.mu.Unlock()
+		p.evicted(t, nil)
+		return false
+	}
+	p.enqueue(t)
+	label, at := t.label, t.submitted // t may be done and recycled once unlocked
+	p.mu.Unlock()
+	p.evicted(nil, victims)
+	p.emitSubmitted(label, at)
+	p.runSerial()
+	return true
+}
+
+// enqueue queues a newly submitted task. It must be called with p.mu held.
+func (p *Pool) enqueue(t *task) {
+	p.reserve(t)
+	t.queued = t.submitted
+	if p.metrics != nil {
+		p.metrics.AddQueued(1)
+	}
+	if t.keyed && !p.acquireKey(t) || t.class != "" && !p.admit(p.classes[t.class], t) {
+		if t.class != "" && p.steal() {
+			p.wakeWorker()
+		}
+		return
+	}
+	p.queue.push(t)
+	p.wakeWorker()
+}
+
+// reserve counts a newly submitted task as pending, without queueing it. It must be called with p.mu held.
+func (p *Pool) reserve(t *task) {
+	if t.onDone == nil {
+		t.order = p.orders
+		p.orders++
+	}
+	p.submissions++
+	t.id = p.submissions
+	p.pending++
+	p.holdTag(t)
+	if t.job != nil {
+		p.jobs[t] = struct{}{}
+	}
+	t.submitted = p.clock.Now()
+	if p.submitTrace {
+		t.origin = callers()
+	}
+}
+
+// requeue queues again a task that is already pending, regardless of the size of the queue
+func (p *Pool) requeue(t *task) {
+	p.mu.Lock()
+	t.queued = p.clock.Now()
+	p.queue.push(t)
+	if p.metrics != nil {
+		p.metrics.AddQueued(1)
+	}
+	p.wakeWorker()
+	p.mu.Unlock()
+	p.runSerial()
+}
+
+// wakeWorker wakes an idle worker up for a newly queued task, and starts a new one if they are all busy.
+// It must be called with p.mu held.
+func (p *Pool) wakeWorker() {
+	switch {
+	case p.compat == V1:
+		p.spawnLegacy()
+	case p.queue.Len() > p.idleWorkers && p.nworkers < p.concurrency:
+		p.spawn()
+	}
+	p.notEmpty.Signal()
+}
+
+// spawnWorkers starts workers for the queued tasks, up to the concurrency. It must be called with p.mu held.
+func (p *Pool) spawnWorkers() {
+	if p.compat == V1 {
+		p.spawnLegacy()
+		return
+	}
+	for p.nworkers < p.concurrency && p.queue.Len() > p.idleWorkers && p.spawn() {
+	}
+}
+
+// spawn starts a new worker, returning false if the pool has no room for its goroutine, see WithMaxGoroutines.
+// It must be called with p.mu held.
+func (p *Pool) spawn() bool {
+	id := p.takeWorkerID()
+	p.nworkers++
+	p.workers.Add(1)
+	if !p.tryGo(func() { p.worker(id) }) {
+		p.nworkers--
+		p.workers.Done()
+		p.workerIDs[id] = false
+		return false
+	}
+	return true
+}
+
+// done marks a pending task as done, t being nil for a child pool, see Child
+func (p *Pool) done(t *task) {
+	p.mu.Lock()
+	if t != nil && t.keyed {
+		p.releaseKey(t.key)
+	}
+	if t != nil && t.class != "" {
+		p.releaseSlot(p.classes[t.class])
+	}
+	if t != nil {
+		p.releaseTag(t)
+		delete(p.jobs, t)
+	}
+	if t != nil && t.recycle {
+		*t = task{}
+		taskPool.Put(t)
+	}
+	p.pending--
+	if p.pending > 0 {
+		p.mu.Unlock()
+		return
+	}
+	for _, c := range p.idleWaiters {
+		close(c)
+	}
+	p.idleWaiters = nil
+	if p.state != stateOpen {
+		p.notEmpty.Broadcast()
+	}
+	onIdle := p.onIdle
+	p.mu.Unlock()
+	for _, fn := range onIdle {
+		fn()
+	}
+}
+
+// unqueue removes t from the queue, or stops its timer, if it's still there, marking it as done. It returns false
+// if t isn't queued.
+func (p *Pool) unqueue(t *task) bool {
+	p.mu.Lock()
+	switch {
+	case t.timer != nil && t.timer.Stop():
+	case p.queue.remove(t):
+		if p.metrics != nil {
+			p.metrics.AddQueued(-1)
+		}
+		p.notFull.Signal()
+	default:
+		p.mu.Unlock()
+		return false
+	}
+	p.stats.Skipped++
+	p.relieved()
+	p.mu.Unlock()
+	p.done(t)
+	return true
+}
+
+// pop removes the next task from the queue, blocking until there is one and there are enough free slots to run it.
+// It returns false when the calling worker must exit: either the pool has too many workers, or the worker has been
+// idle for too long, or the pool is closed and no task is pending anymore.
+func (p *Pool) pop() (*task, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var idleSince time.Time
+	var idle Timer
+	for !p.runnable() && !(p.state != stateOpen && p.pending == 0) && p.nworkers <= p.concurrency {
+		if p.steal() {
+			continue
+		}
+		if p.idleTimeout > 0 {
+			if idleSince.IsZero() {
+				idleSince = p.clock.Now()
+				idle = p.clock.AfterFunc(p.idleTimeout, p.wakeAll)
+			} else if p.since(idleSince) >= p.idleTimeout {
+				break
+			}
+		}
+		p.idleWorkers++
+		p.notEmpty.Wait()
+		p.idleWorkers--
+	}
+	if idle != nil {
+		idle.Stop() // not to pile timers up on busy pools with a long idle timeout
+	}
+	if p.nworkers > p.concurrency || !p.runnable() {
+		p.nworkers--
+		return nil, false
+	}
+	return p.claim(), true
+}
+
+// claim removes the next task from the queue, taking its slots. It must be called with p.mu held, once runnable.
+func (p *Pool) claim() *task {
+	t := p.replayed()
+	p.take(t)
+	t.units = min(max(t.weight, 1), p.concurrency)
+	p.used += t.units
+	if p.metrics != nil {
+		p.metrics.AddQueued(-1)
+	}
+	p.notFull.Signal()
+	if p.runnable() {
+		p.notEmpty.Signal()
+	}
+	return t
+}
+
+// Resize changes the number of functions that can work at the same time, n being at least 1. When the limit is raised
+// new workers are started for the queued functions. When the limit is lowered the running functions are not interrupted:
+// the extra workers exit as soon as they are done with their current function.
+func (p *Pool) Resize(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n < 1 || p.replay != nil || p.serial {
+		n = 1
+	}
+	p.concurrency = n
+	p.relieved()
+	if p.state != stateOpen && p.pending == 0 {
+		// the workers are exiting, or are gone already
+		return
+	}
+	p.spawnWorkers()
+	p.notEmpty.Broadcast()
+	p.slotFree.Broadcast()
+}
+
+func (p *Pool) worker(id int) {
+	defer p.workers.Done()
+	if p.lockOSThread {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	w := p.initWorker(id)
+	defer func() {
+		if !w.abandoned {
+			p.teardownWorker(w)
+		}
+	}()
+	for {
+		t, ok := p.pop()
+		if !ok {
+			return
+		}
+		units := t.units // t may be recycled once executed
+		w.current = t
+		p.execute(w, t)
+		w.current = nil
+		p.releaseUnits(units)
+		if w.abandoned {
+			p.teardownWorker(w) // freeing its ID for the worker replacing it
+			p.mu.Lock()
+			p.nworkers--
+			p.wakeWorker() // in its place
+			p.mu.Unlock()
+			return
+		}
+		p.yieldBetweenTasks()
+	}
+}
+
+// execute runs t on w, skipping it if its context is already done
+func (p *Pool) execute(w *worker, t *task) {
+	ctx := w.ctx
+	if t.ctx != nil {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(t.ctx)
+		defer cancel(nil)
+		defer p.propagateCause(cancel)()
+		ctx = context.WithValue(p.withBaseValues(ctx), workerKey{}, w)
+	}
+	if t.meta != nil {
+		ctx = context.WithValue(ctx, metaKey{}, t.meta)
+	}
+	if p.rateLimiter != nil && ctx.Err() == nil {
+		p.rateLimiter.wait(ctx) // it only fails if ctx is done, then t is skipped below
+	}
+	if p.parent != nil && ctx.Err() == nil {
+		if release, err := p.parent.acquire(ctx, t.units); err == nil {
+			defer release()
+		}
+	}
+	if len(p.limiters) > 0 && ctx.Err() == nil {
+		release, err := p.acquireLimiters(ctx)
+		if err != nil && ctx.Err() == nil {
+			p.abandon(t, err)
+			return
+		}
+		if err == nil {
+			defer release()
+		}
+	}
+	if p.maxErrors > 0 && ctx.Err() == nil && p.tooManyFailures() {
+		p.abandon(t, ErrTooManyFailures)
+		return
+	}
+	if ctx.Err() != nil || p.ctx.Err() != nil {
+		p.skipped(t)
+		switch {
+		case t.err != nil:
+			p.finish(t, t.err)
+		case t.onDone != nil:
+			t.onDone(cmp.Or(context.Cause(ctx), p.ctx.Err()))
+			p.done(t)
+		default:
+			p.finish(t, nil)
+		}
+		return
+	}
+
+	t.goroutine = w.goroutine
+	start := p.started(t)
+	ctx, timeout, cancel := p.withDeadline(ctx, t, start)
+	defer cancel()
+	deadlineCtx := ctx
+	ctx, span := p.startSpan(ctx, t, start)
+	var slow Timer
+	if p.slowThreshold > 0 {
+		label := t.label
+		slow = p.clock.AfterFunc(p.slowThreshold, func() { p.onSlowTask(label, p.since(start)) })
+	}
+	var allocs uint64
+	if p.allocStats {
+		allocs = heapAllocs()
+	}
+	err := w.err
+	if err == nil {
+		err = p.supervised(ctx, w, t, start)
+	}
+	if timeout > 0 {
+		err = timedOut(deadlineCtx, timeout, err)
+	}
+	if slow != nil {
+		slow.Stop()
+	}
+	if p.allocStats {
+		allocs = heapAllocs() - allocs
+	}
+	if span != nil {
+		span.End(err)
+	}
+	info := TaskInfo{
+		Pool:      p.name,
+		Label:     t.label,
+		Submitted: t.submitted,
+		Start:     start,
+		Duration:  p.since(start),
+		Attempt:   t.attempt + 1,
+		Seq:       t.id,
+		Allocs:    allocs,
+		Err:       err,
+		Meta:      t.meta,
+	}
+	if err != nil {
+		info.Origin = t.where()
+		err = &TaskError{TaskInfo: info}
+	}
+	retried := err != nil && p.retry(ctx, t, err)
+	p.ended(t, info, retried)
+	if !retried {
+		p.tripCircuit(t.label, err)
+		p.finish(t, err)
+	}
+}
+
+// finish delivers the final outcome of t, to its handle if it has one or else to the channels of the pool,
+// and marks t as done
+func (p *Pool) finish(t *task, err error) {
+	if t.cleanup != nil {
+		t.cleanup()
+	}
+	if err != nil && t.onDone == nil && p.errorFilter != nil {
+		err = p.errorFilter(err)
+	}
+	if err != nil {
+		p.failed(err)
+	}
+	if t.onDone != nil {
+		if t.output != nil {
+			t.output()
+		}
+		t.onDone(err)
+		p.done(t)
+		return
+	}
+	if p.ordered {
+		p.deliverInOrder(t.order, func() { p.deliver(t, err) })
+		return
+	}
+	p.deliver(t, err)
+}
+
+// deliver sends the error of t to Errors, or its value if it has one, and marks t as done
+func (p *Pool) deliver(t *task, err error) {
+	if t.output != nil && (err == nil || t.outputFailures) {
+		t.output()
+	} else if err != nil {
+		p.send(err)
+	}
+	p.done(t)
+}
+
+// call runs the function of t, turning a panic into an error
+func (p *Pool) call(ctx context.Context, t *task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = p.recovered(t, r)
+		}
+	}()
+	if t.locked {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	if t.run != nil {
+		t.run()
+		return nil
+	}
+	if t.produce != nil {
+		setOutput(ctx, t, nil) // not to deliver the value of a previous attempt if this one panics
+		output, err := t.produce(ctx)
+		setOutput(ctx, t, output)
+		return err
+	}
+	return t.fn(ctx)
+}
@@ -0,0 +1,38 @@
+package cc
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResizeGrow(t *testing.T) {
+	p := New(1)
+	errs := drain(p)
+	var together sync.WaitGroup
+	together.Add(4)
+	for range 4 {
+		p.Run(func() {
+			together.Done()
+			together.Wait() // only returning once the 4 functions run at the same time
+		})
+	}
+	p.Resize(4)
+	p.Wait()
+	within(t, 5*time.Second, func() { errs() })
+}
+
+func TestResizeShrink(t *testing.T) {
+	p := New(4)
+	errs := drain(p)
+	p.Resize(1)
+	var pk peak
+	for range 10 {
+		p.Run(func() { pk.run(time.Millisecond) })
+	}
+	p.Wait()
+	errs()
+	if pk.high != 1 {
+		t.Errorf("%d functions ran at the same time after resizing the pool to 1", pk.high)
+	}
+}
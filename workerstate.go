@@ -0,0 +1,105 @@
+package cc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// worker holds the state of a worker goroutine
+type worker struct {
+	id        int
+	goroutine uint64          // the ID of the goroutine of the worker, see Dump
+	ctx       context.Context // the context of the pool, carrying the worker
+	state     any
+	err       error         // the error of the init hook
+	pool      *Pool         // the pool of the worker, see BufferFrom
+	buf       *bytes.Buffer // the buffer reused by the functions, see BufferFrom
+	current   *task         // the task running, see Yield
+	abandoned bool          // whether the worker left its task running, see WithEscalation
+}
+
+type workerKey struct{}
+
+// WithWorkerInit makes each worker of the pool call fn when it starts, with an ID between 0 and the concurrency of the
+// pool, unique among the workers alive. The state returned by fn is given to the functions run with RunWithState on
+// that worker, so it can hold resources that are expensive to set up or not safe for concurrent use, like a connection.
+// If fn fails, every function picked up by the worker fails with that error.
+func WithWorkerInit(fn func(workerID int) (any, error)) Option {
+	return func(p *Pool) {
+		p.workerInit = fn
+	}
+}
+
+// WithWorkerTeardown makes each worker of the pool call fn with the state returned by the init hook when it exits,
+// unless the init hook failed.
+func WithWorkerTeardown(fn func(workerID int, state any)) Option {
+	return func(p *Pool) {
+		p.workerTeardown = fn
+	}
+}
+
+// RunWithState is like RunCtx, but fn also receives the state of the worker running it, see WithWorkerInit
+func (p *Pool) RunWithState(fn func(ctx context.Context, state any) error) error {
+	return p.push(&task{fn: func(ctx context.Context) error {
+		return fn(ctx, workerFrom(ctx).state)
+	}})
+}
+
+// WorkerID returns the ID of the worker running the function that received ctx, or -1 if ctx doesn't come from a
+// pool. Like the IDs given to WithWorkerInit it's the lowest ID not taken by the workers alive, so between 0 and the
+// concurrency of the pool, unless the pool was shrunk and its extra workers didn't exit yet, see Resize. It lets the
+// functions shard their work by worker, e.g. with an output file or a connection each, without synchronizing, with
+// two exceptions where two functions run with the same ID at once: the attempts of RunHedged share the ID of their
+// worker, and the ID of a worker that abandoned its function, see WithEscalation, is given to the worker replacing it
+// while the function may still be running.
+func WorkerID(ctx context.Context) int {
+	if w := workerFrom(ctx); w != nil {
+		return w.id
+	}
+	return -1
+}
+
+// workerFrom returns the worker running the task that received ctx
+func workerFrom(ctx context.Context) *worker {
+	w, _ := ctx.Value(workerKey{}).(*worker)
+	return w
+}
+
+// takeWorkerID returns the lowest worker ID not taken. It must be called with p.mu held.
+func (p *Pool) takeWorkerID() int {
+	id := slices.Index(p.workerIDs, false)
+	if id < 0 {
+		id = len(p.workerIDs)
+		p.workerIDs = append(p.workerIDs, true)
+	}
+	p.workerIDs[id] = true
+	return id
+}
+
+func (p *Pool) initWorker(id int) *worker {
+	w := &worker{id: id, goroutine: currentGoroutineID(), pool: p}
+	w.ctx = context.WithValue(p.withBaseValues(p.ctx), workerKey{}, w)
+	if p.workerInit != nil {
+		w.state, w.err = p.workerInit(id)
+		if w.err != nil {
+			w.err = fmt.Errorf("cc: init of worker %d: %w", id, w.err)
+		}
+	}
+	p.mu.Lock()
+	p.initialized++
+	p.notifyWarm()
+	p.mu.Unlock()
+	return w
+}
+
+func (p *Pool) teardownWorker(w *worker) {
+	if p.workerTeardown != nil && w.err == nil && !w.abandoned {
+		p.workerTeardown(w.id, w.state)
+	}
+	p.mu.Lock()
+	p.workerIDs[w.id] = false
+	p.initialized--
+	p.mu.Unlock()
+}
@@ -0,0 +1,74 @@
+package cc
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerIDUnique(t *testing.T) {
+	p := New(4)
+	var mu sync.Mutex
+	running := map[int]bool{}
+	for range 200 {
+		p.RunCtx(context.Background(), func(ctx context.Context) error {
+			id := WorkerID(ctx)
+			mu.Lock()
+			if running[id] || id < 0 || id >= 4 {
+				t.Errorf("ID %d taken twice or out of range", id)
+			}
+			running[id] = true
+			mu.Unlock()
+			time.Sleep(100 * time.Microsecond)
+			mu.Lock()
+			running[id] = false
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := p.WaitErr(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkerIDAfterAbandon(t *testing.T) {
+	var mu sync.Mutex
+	var inits []int
+	release := make(chan struct{})
+	p := New(1, abandoning(release), WithWorkerInit(func(id int) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		inits = append(inits, id)
+		return nil, nil
+	}))
+	ids := make(chan int, 2)
+	p.RunCtx(context.Background(), func(ctx context.Context) error {
+		ids <- WorkerID(ctx)
+		<-release // ignoring its cancellation until abandoned
+		return nil
+	})
+	p.RunCtx(context.Background(), func(ctx context.Context) error {
+		ids <- WorkerID(ctx)
+		return nil
+	})
+	go func() {
+		for range p.Errors {
+		}
+	}()
+	p.Wait()
+	select {
+	case <-p.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the pool never ended")
+	}
+	if first, second := <-ids, <-ids; first != 0 || second != 0 {
+		t.Errorf("got IDs %d and %d, want the replacing worker to take the ID 0 freed", first, second)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(inits, []int{0, 0}) {
+		t.Errorf("workers initialized with the IDs %v, want [0 0]", inits)
+	}
+}
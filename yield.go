@@ -0,0 +1,47 @@
+package cc
+
+import (
+	"context"
+	"runtime"
+)
+
+// WithPreemption lets the long functions of the pool give way to the functions queued with a higher priority when they
+// call Yield, see RunPriority, so that urgent work doesn't wait for the long functions to end.
+func WithPreemption() Option {
+	return func(p *Pool) {
+		p.preemption = true
+	}
+}
+
+// Yield is meant to be called periodically by the long CPU-bound functions, with the context they received. In a pool
+// created with WithPreemption, if the next queued function has a higher priority than the calling one, and takes no
+// more slots, Yield runs it right away in the slots of the calling function, and returns once it's over.
+// Otherwise it just yields the processor, see runtime.Gosched. It returns the error of ctx, so that the function can
+// stop if it's done.
+func Yield(ctx context.Context) error {
+	w := workerFrom(ctx)
+	if w == nil || !w.pool.preemption || w.current == nil {
+		runtime.Gosched()
+		return ctx.Err()
+	}
+	p, cur := w.pool, w.current
+	p.mu.Lock()
+	next := p.replayed()
+	if next == nil || next.priority <= cur.priority || p.paused || min(max(next.weight, 1), p.concurrency) > cur.units {
+		p.mu.Unlock()
+		runtime.Gosched()
+		return ctx.Err()
+	}
+	p.take(next)
+	next.units = 0 // it runs in the slots of cur
+	if p.metrics != nil {
+		p.metrics.AddQueued(-1)
+	}
+	p.notFull.Signal()
+	p.mu.Unlock()
+
+	w.current = next
+	p.execute(w, next)
+	w.current = cur
+	return ctx.Err()
+}